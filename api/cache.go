@@ -0,0 +1,70 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"golang.org/x/oauth2"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// tokenCache stores the token of a password grant on disk, so it can be reused by later terraform runs.
+type tokenCache struct {
+	path string
+}
+
+// newTokenCache returns the cache of the token for tokenURL, clientID and username in the user cache directory.
+func newTokenCache(tokenURL string, clientID string, username string) (*tokenCache, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	key := sha256.Sum256([]byte(tokenURL + "\x00" + clientID + "\x00" + username))
+	return &tokenCache{
+		path: filepath.Join(dir, "terraform-provider-plusserver", "tokens", hex.EncodeToString(key[:])+".json"),
+	}, nil
+}
+
+// load returns the cached token or nil if there is none.
+func (c *tokenCache) load() *oauth2.Token {
+	data, err := ioutil.ReadFile(c.path)
+	if err != nil {
+		return nil
+	}
+	var token oauth2.Token
+	if err = json.Unmarshal(data, &token); err != nil || token.AccessToken == "" {
+		return nil
+	}
+	return &token
+}
+
+// save replaces the cached token. The file is only readable by the current user.
+func (c *tokenCache) save(token *oauth2.Token) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(c.path)
+	if err = os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	file, err := ioutil.TempFile(dir, "token-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if err = file.Chmod(0600); err != nil {
+		file.Close()
+		return err
+	}
+	if _, err = file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), c.path)
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"golang.org/x/oauth2"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTokenCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	cache, err := newTokenCache("https://auth.example.com/token", "terraform", "jane")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token := cache.load(); token != nil {
+		t.Fatalf("load() = %v before a token was saved, want nil", token)
+	}
+
+	expiry := time.Now().Add(time.Hour).Round(time.Second)
+	if err = cache.save(&oauth2.Token{AccessToken: "eyJ", RefreshToken: "refresh", Expiry: expiry}); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(cache.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("token file mode = %o, want 600", mode)
+	}
+	dirInfo, err := os.Stat(filepath.Dir(cache.path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := dirInfo.Mode().Perm(); mode != 0700 {
+		t.Errorf("token directory mode = %o, want 700", mode)
+	}
+
+	token := cache.load()
+	if token == nil || token.AccessToken != "eyJ" || token.RefreshToken != "refresh" || !token.Expiry.Equal(expiry) {
+		t.Errorf("load() = %v, want the saved token", token)
+	}
+
+	other, err := newTokenCache("https://auth.example.com/token", "terraform", "john")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.path == cache.path {
+		t.Error("the tokens of different users are cached in the same file")
+	}
+}
+
+func TestNewClientReusesCachedToken(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	tokens := newTokenServer(t, 300, false)
+	config := tokens.config()
+	config.CacheToken = true
+
+	for run := 1; run <= 2; run++ {
+		if _, err := NewClient(context.Background(), config, nil); err != nil {
+			t.Fatalf("run %d: %s", run, err)
+		}
+	}
+	if password, refresh := tokens.grants(); password != 1 || refresh != 0 {
+		t.Errorf("%d password grants and %d refresh grants, want the cached token to be reused", password, refresh)
+	}
+}
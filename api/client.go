@@ -5,31 +5,47 @@ import (
 	"context"
 	"fmt"
 	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"io"
 	"io/ioutil"
-	"log"
 	"net/http"
 )
 
+// DefaultTransactionCaller is sent as X-Transaction-Caller if no caller is configured.
+const DefaultTransactionCaller = "terraform-provider-plusserver"
+
 type Client struct {
 	baseURL string
 	apiService string
 	httpClient *http.Client
+	transactionCaller string
+	transactionPrefix string
 }
 
 type IClient interface {
 	MakeRequest(ctx context.Context, method string, endpoint string, body *bytes.Buffer) (closer io.ReadCloser, err error)
 }
 
-func NewHTTPClient(credentials *OAuthConfig, apiService string, baseURL string) (*Client, error) {
-	client, err := NewClient(credentials)
+func NewHTTPClient(ctx context.Context, credentials *OAuthConfig, httpConfig *HTTPConfig, apiService string, baseURL string) (*Client, error) {
+	client, err := NewClient(ctx, credentials, httpConfig)
 	if err != nil {
-		log.Printf("[ERROR] %s", err.Error())
+		tflog.SubsystemError(WithLogSubsystem(ctx, SubsystemAuth), SubsystemAuth, "unable to authenticate", map[string]interface{}{
+			"error": err.Error(),
+		})
 		return nil, err
 	}
 
+	transactionCaller := DefaultTransactionCaller
+	if httpConfig != nil && httpConfig.TransactionCaller != "" {
+		transactionCaller = httpConfig.TransactionCaller
+	}
+	var transactionPrefix string
+	if httpConfig != nil {
+		transactionPrefix = httpConfig.TransactionPrefix
+	}
+
 	return &Client{httpClient: client, baseURL: baseURL,
-		apiService: apiService}, nil
+		apiService: apiService, transactionCaller: transactionCaller, transactionPrefix: transactionPrefix}, nil
 }
 
 func (c *Client) makeURL(endpoint string) string {
@@ -37,8 +53,22 @@ func (c *Client) makeURL(endpoint string) string {
 }
 
 func (c *Client) MakeRequest(ctx context.Context, method string, endpoint string, body *bytes.Buffer) (closer io.ReadCloser, err error) {
-	var req *http.Request
-	req, err = http.NewRequestWithContext(ctx, method, c.makeURL(endpoint), body)
+	resp, err := c.Send(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Send works like MakeRequest but returns the whole response. The caller has to close the response body.
+func (c *Client) Send(ctx context.Context, method string, endpoint string, body *bytes.Buffer) (*http.Response, error) {
+	messageId := uuid.New().String()
+	transactionId := c.newTransactionId()
+	ctx = tflog.SetField(ctx, "message_id", messageId)
+	ctx = tflog.SetField(ctx, "transaction_id", transactionId)
+	ctx = WithLogSubsystem(ctx, SubsystemAPI)
+
+	req, err := http.NewRequestWithContext(ctx, method, c.makeURL(endpoint), body)
 	if err != nil {
 		return nil, err
 	}
@@ -48,21 +78,33 @@ func (c *Client) MakeRequest(ctx context.Context, method string, endpoint string
 		default:
 			req.Header.Add("Content-Type", "application/json")
 	}
-	req.Header.Set("X-Message-Id", uuid.New().String())
-	req.Header.Set("X-Transaction-Id", uuid.New().String())
-	req.Header.Set("X-Transaction-Caller", "terraform-provider-plusserver")
+	req.Header.Set("X-Message-Id", messageId)
+	req.Header.Set("X-Transaction-Id", transactionId)
+	req.Header.Set("X-Transaction-Caller", c.transactionCaller)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, &RequestError{Method: method, URL: req.URL.String(), MessageId: messageId,
+			TransactionId: transactionId, Err: err}
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("got a non 200 status code: %v", resp.StatusCode)
-		}
-		return nil, fmt.Errorf("got a non 200 status code: %v - %s URL: %s", resp.StatusCode, string(bodyBytes), resp.Request.URL)
+		tflog.SubsystemWarn(ctx, SubsystemAPI, "request failed", map[string]interface{}{
+			"method":   method,
+			"endpoint": endpoint,
+			"status":   resp.StatusCode,
+		})
+		defer resp.Body.Close()
+		bodyBytes, _ := ioutil.ReadAll(resp.Body)
+		return nil, &RequestError{Method: method, URL: resp.Request.URL.String(), StatusCode: resp.StatusCode,
+			Body: string(bodyBytes), MessageId: messageId, TransactionId: transactionId}
 	}
-	return resp.Body, nil
+	return resp, nil
+}
+
+func (c *Client) newTransactionId() string {
+	if c.transactionPrefix == "" {
+		return uuid.New().String()
+	}
+	return fmt.Sprintf("%s-%s", c.transactionPrefix, uuid.New().String())
 }
\ No newline at end of file
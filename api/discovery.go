@@ -0,0 +1,48 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type openIDConfiguration struct {
+	Issuer        string `json:"issuer"`
+	TokenEndpoint string `json:"token_endpoint"`
+}
+
+// DiscoverTokenURL fetches the OpenID configuration of issuerURL and returns its token endpoint.
+func DiscoverTokenURL(ctx context.Context, client *http.Client, issuerURL string) (string, error) {
+	discoveryURL := strings.TrimSuffix(issuerURL, "/") + "/.well-known/openid-configuration"
+	tflog.SubsystemDebug(ctx, SubsystemAuth, "discovering token endpoint", map[string]interface{}{
+		"issuer_url": issuerURL,
+	})
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("unable to fetch %s: %w", discoveryURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unable to fetch %s: got status code %d", discoveryURL, resp.StatusCode)
+	}
+	var configuration openIDConfiguration
+	if err = json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&configuration); err != nil {
+		return "", fmt.Errorf("invalid openid configuration at %s: %w", discoveryURL, err)
+	}
+	if configuration.TokenEndpoint == "" {
+		return "", errors.New("the openid configuration has no token_endpoint")
+	}
+	return configuration.TokenEndpoint, nil
+}
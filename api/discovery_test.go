@@ -0,0 +1,58 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDiscoverTokenURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "token endpoint",
+			status: http.StatusOK,
+			body:   `{"issuer":"https://auth.example.com/realms/plusserver","token_endpoint":"https://auth.example.com/realms/plusserver/protocol/openid-connect/token"}`,
+			want:   "https://auth.example.com/realms/plusserver/protocol/openid-connect/token",
+		},
+		{name: "no token endpoint", status: http.StatusOK, body: `{"issuer":"https://auth.example.com"}`, wantErr: "no token_endpoint"},
+		{name: "no JSON", status: http.StatusOK, body: `<html></html>`, wantErr: "invalid openid configuration"},
+		{name: "not found", status: http.StatusNotFound, body: `{}`, wantErr: "got status code 404"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var path string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			got, err := DiscoverTokenURL(context.Background(), server.Client(), server.URL+"/realms/plusserver/")
+			if path != "/realms/plusserver/.well-known/openid-configuration" {
+				t.Errorf("requested %s, want the openid configuration of the issuer", path)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("DiscoverTokenURL() error = %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("DiscoverTokenURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
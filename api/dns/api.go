@@ -0,0 +1,20 @@
+package dns
+
+import (
+	"context"
+)
+
+// API lists the calls of the DNS entity service. It is implemented by Client and by mock.API for tests.
+type API interface {
+	SearchDomains(ctx context.Context, domain string) (*SearchDomainResponse, error)
+	GetDomainById(ctx context.Context, domainId string) (*DomainResponse, error)
+	CreateDomain(ctx context.Context, req *CreateDomain) (*DomainResponse, error)
+	UpdateDomain(ctx context.Context, domainId string, req *UpdateDomain) (*DomainResponse, error)
+	DeleteDomain(ctx context.Context, domainId string) (*DeleteDomainResponse, error)
+	GetRecords(ctx context.Context, domainId int) (*RecordsResponse, error)
+	CreateRecord(ctx context.Context, records *RecordCreateRequest) (*RecordsResponse, error)
+	UpdateRecord(ctx context.Context, domainId int, recordId string, content string, ttl int) (*RecordUpdateResponse, error)
+	DeleteRecord(ctx context.Context, domainId int, recordId string) (*RecordUpdateResponse, error)
+}
+
+var _ API = (*Client)(nil)
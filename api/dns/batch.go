@@ -0,0 +1,200 @@
+package dns
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/plusserver/terraform-provider-plusserver/api"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxRecordBatchSize is the number of records after which a batch is sent without waiting for the window to end.
+const MaxRecordBatchSize = 100
+
+// BatchClient combines the record creations of a domain into a single CreateRecord call. The first creation of a
+// domain opens a batch, all creations of the same domain arriving within the window are added to it and the batch
+// is sent when the window ends. All other calls are passed to the wrapped API.
+//
+// Every caller of a batch gets the response of the combined call. If the combined call fails, every caller creates
+// its own records with a separate call, so a single rejected record doesn't fail the records of other callers.
+type BatchClient struct {
+	API
+	window time.Duration
+
+	mu      sync.Mutex
+	batches map[int]*recordBatch
+}
+
+// recordBatch collects the record creations of a domain. pending, sent and waiting are guarded by the mutex of
+// the BatchClient, resp and err are set before done is closed.
+type recordBatch struct {
+	// ctx is cancelled once all callers have given up waiting for the batch
+	ctx     context.Context
+	cancel  context.CancelFunc
+	pending []*RecordCreateRequest
+	sent    bool
+	waiting int
+	timer   *time.Timer
+
+	done  chan struct{}
+	calls int
+	resp  *RecordsResponse
+	err   error
+}
+
+var _ API = (*BatchClient)(nil)
+
+// NewBatchClient returns a BatchClient collecting record creations for window.
+func NewBatchClient(client API, window time.Duration) *BatchClient {
+	return &BatchClient{API: client, window: window, batches: map[int]*recordBatch{}}
+}
+
+// CreateRecord adds the records to the batch of their domain and waits until the batch has been sent or ctx is
+// done. Requests with records of several domains are sent right away.
+func (c *BatchClient) CreateRecord(ctx context.Context, records *RecordCreateRequest) (*RecordsResponse, error) {
+	if len(records.DnsResourceRecordList) == 0 {
+		return c.API.CreateRecord(ctx, records)
+	}
+	domainId := records.DnsResourceRecordList[0].DnsDomainId
+	for _, record := range records.DnsResourceRecordList {
+		if record.DnsDomainId != domainId {
+			return c.API.CreateRecord(ctx, records)
+		}
+	}
+
+	c.mu.Lock()
+	batch, ok := c.batches[domainId]
+	if !ok {
+		// the batch outlives the context of the caller opening it, it keeps its log fields though
+		batchCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		batch = &recordBatch{ctx: batchCtx, cancel: cancel, done: make(chan struct{})}
+		batch.timer = time.AfterFunc(c.window, func() { c.flush(domainId, batch) })
+		c.batches[domainId] = batch
+	}
+	batch.pending = append(batch.pending, records)
+	batch.waiting++
+	if batch.size() >= MaxRecordBatchSize && batch.timer.Stop() {
+		delete(c.batches, domainId)
+		go c.flush(domainId, batch)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-batch.done:
+	case <-ctx.Done():
+		c.leave(domainId, batch, records)
+		return nil, ctx.Err()
+	}
+	if batch.err != nil && batch.calls > 1 {
+		tflog.SubsystemWarn(ctx, api.SubsystemDNS, "batched record creation failed, creating the records separately",
+			map[string]interface{}{"domain_id": domainId, "error": batch.err.Error()})
+		return c.createSeparately(ctx, records)
+	}
+	return batch.resp, batch.err
+}
+
+// leave withdraws the records of a caller which stopped waiting. Records of a batch which hasn't been sent yet are
+// taken out of it. The request of the batch is cancelled once no caller is waiting for it anymore.
+func (c *BatchClient) leave(domainId int, batch *recordBatch, records *RecordCreateRequest) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	batch.waiting--
+	if !batch.sent {
+		for i, pending := range batch.pending {
+			if pending == records {
+				batch.pending = append(batch.pending[:i], batch.pending[i+1:]...)
+				break
+			}
+		}
+	}
+	if batch.waiting > 0 {
+		return
+	}
+	if !batch.sent && batch.timer.Stop() {
+		if c.batches[domainId] == batch {
+			delete(c.batches, domainId)
+		}
+		close(batch.done)
+	}
+	batch.cancel()
+}
+
+// flush sends the batch of a domain and wakes up its callers.
+func (c *BatchClient) flush(domainId int, batch *recordBatch) {
+	c.mu.Lock()
+	if c.batches[domainId] == batch {
+		delete(c.batches, domainId)
+	}
+	batch.sent = true
+	var records []Record
+	for _, pending := range batch.pending {
+		records = append(records, pending.DnsResourceRecordList...)
+	}
+	batch.calls = len(batch.pending)
+	c.mu.Unlock()
+	defer batch.cancel()
+
+	if len(records) == 0 {
+		// all callers left before the window ended
+		close(batch.done)
+		return
+	}
+	ctx := logContext(batch.ctx, map[string]interface{}{"domain_id": domainId})
+	tflog.SubsystemDebug(ctx, api.SubsystemDNS, "creating batched records", map[string]interface{}{
+		"count": len(records),
+		"calls": batch.calls,
+	})
+	batch.resp, batch.err = c.API.CreateRecord(ctx, NewRecordCreateRequest(records...))
+	close(batch.done)
+}
+
+func (b *recordBatch) size() int {
+	size := 0
+	for _, pending := range b.pending {
+		size += len(pending.DnsResourceRecordList)
+	}
+	return size
+}
+
+// createSeparately creates the records of a single caller after its batch failed. The api may have created some
+// records of the batch before rejecting it, so records which exist already are returned instead of being created
+// twice.
+func (c *BatchClient) createSeparately(ctx context.Context, records *RecordCreateRequest) (*RecordsResponse, error) {
+	existing, err := c.API.GetRecords(ctx, records.DnsResourceRecordList[0].DnsDomainId)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []*Record
+	var missing []Record
+	for _, record := range records.DnsResourceRecordList {
+		if match := findSameRecord(existing.DnsResourceRecordList, record); match != nil {
+			found = append(found, match)
+		} else {
+			missing = append(missing, record)
+		}
+	}
+	if len(missing) == 0 {
+		return &RecordsResponse{DnsResourceRecordList: found}, nil
+	}
+
+	created, err := c.API.CreateRecord(ctx, NewRecordCreateRequest(missing...))
+	if err != nil {
+		return nil, err
+	}
+	created.DnsResourceRecordList = append(found, created.DnsResourceRecordList...)
+	return created, nil
+}
+
+// findSameRecord returns the record with the name, type and content of record.
+func findSameRecord(records []*Record, record Record) *Record {
+	for _, existing := range records {
+		if strings.EqualFold(existing.Name, record.Name) && existing.Type == record.Type &&
+			existing.Content == record.Content {
+			return existing
+		}
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package dns_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/plusserver/terraform-provider-plusserver/api/dns"
+	"github.com/plusserver/terraform-provider-plusserver/api/dns/mock"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBatchClientCreateRecord(t *testing.T) {
+	tests := []struct {
+		name string
+		// records are created concurrently, one call per record
+		records []dns.Record
+		// failing content makes the combined call and every single call with that content fail
+		failing string
+		// existing records are returned by GetRecords
+		existing     []*dns.Record
+		wantErrors   []bool
+		wantCreates  int
+		wantRequests [][]string
+	}{
+		{
+			name:        "single record",
+			records:     []dns.Record{{DnsDomainId: 1, Name: "www", Type: "A", Content: "10.0.0.1"}},
+			wantErrors:  []bool{false},
+			wantCreates: 1,
+		},
+		{
+			name: "records of a domain are combined",
+			records: []dns.Record{
+				{DnsDomainId: 1, Name: "a", Type: "A", Content: "10.0.0.1"},
+				{DnsDomainId: 1, Name: "b", Type: "A", Content: "10.0.0.2"},
+				{DnsDomainId: 1, Name: "c", Type: "A", Content: "10.0.0.3"},
+			},
+			wantErrors:  []bool{false, false, false},
+			wantCreates: 1,
+		},
+		{
+			name: "records of different domains are not combined",
+			records: []dns.Record{
+				{DnsDomainId: 1, Name: "a", Type: "A", Content: "10.0.0.1"},
+				{DnsDomainId: 2, Name: "b", Type: "A", Content: "10.0.0.2"},
+			},
+			wantErrors:  []bool{false, false},
+			wantCreates: 2,
+		},
+		{
+			name: "a failed batch is retried record by record",
+			records: []dns.Record{
+				{DnsDomainId: 1, Name: "a", Type: "A", Content: "10.0.0.1"},
+				{DnsDomainId: 1, Name: "b", Type: "A", Content: "invalid"},
+				{DnsDomainId: 1, Name: "c", Type: "A", Content: "10.0.0.3"},
+			},
+			failing:     "invalid",
+			wantErrors:  []bool{false, true, false},
+			wantCreates: 4,
+		},
+		{
+			name: "records created by a failed batch are not created twice",
+			records: []dns.Record{
+				{DnsDomainId: 1, Name: "a", Type: "A", Content: "10.0.0.1"},
+				{DnsDomainId: 1, Name: "b", Type: "A", Content: "invalid"},
+			},
+			failing: "invalid",
+			existing: []*dns.Record{
+				{DnsResourceRecordId: "1", DnsDomainId: 1, Name: "A", Type: "A", Content: "10.0.0.1"},
+			},
+			wantErrors:  []bool{false, true},
+			wantCreates: 2,
+		},
+		{
+			name:        "a single failed record is not retried",
+			records:     []dns.Record{{DnsDomainId: 1, Name: "a", Type: "A", Content: "invalid"}},
+			failing:     "invalid",
+			wantErrors:  []bool{true},
+			wantCreates: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &mock.API{
+				CreateRecordFunc: func(ctx context.Context, req *dns.RecordCreateRequest) (*dns.RecordsResponse, error) {
+					resp := &dns.RecordsResponse{}
+					for i, record := range req.DnsResourceRecordList {
+						if tt.failing != "" && record.Content == tt.failing {
+							return nil, errors.New("invalid content")
+						}
+						record.DnsResourceRecordId = fmt.Sprintf("new-%d", i)
+						resp.DnsResourceRecordList = append(resp.DnsResourceRecordList, &record)
+					}
+					return resp, nil
+				},
+				GetRecordsFunc: func(ctx context.Context, domainId int) (*dns.RecordsResponse, error) {
+					return &dns.RecordsResponse{DnsResourceRecordList: tt.existing}, nil
+				},
+			}
+			batchClient := dns.NewBatchClient(client, 20*time.Millisecond)
+
+			errs := make([]error, len(tt.records))
+			var wg sync.WaitGroup
+			for i, record := range tt.records {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					resp, err := batchClient.CreateRecord(context.Background(), dns.NewRecordCreateRequest(record))
+					if err == nil && len(resp.DnsResourceRecordList) == 0 {
+						err = errors.New("empty response")
+					}
+					errs[i] = err
+				}()
+			}
+			wg.Wait()
+
+			for i, err := range errs {
+				if (err != nil) != tt.wantErrors[i] {
+					t.Errorf("record %s: got error %v, want error %v", tt.records[i].Name, err, tt.wantErrors[i])
+				}
+			}
+			if creates := countCalls(client, "CreateRecord"); creates != tt.wantCreates {
+				t.Errorf("got %d CreateRecord calls, want %d", creates, tt.wantCreates)
+			}
+		})
+	}
+}
+
+func TestBatchClientCreateRecordContextDone(t *testing.T) {
+	client := &mock.API{
+		CreateRecordFunc: func(ctx context.Context, req *dns.RecordCreateRequest) (*dns.RecordsResponse, error) {
+			return &dns.RecordsResponse{}, nil
+		},
+	}
+	batchClient := dns.NewBatchClient(client, time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := batchClient.CreateRecord(ctx, dns.NewRecordCreateRequest(dns.Record{DnsDomainId: 1, Name: "www"}))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("CreateRecord returned after %s, want it to return when the context is done", elapsed)
+	}
+
+	// the record has been taken out of the batch, so nothing is sent when the window ends
+	time.Sleep(1100 * time.Millisecond)
+	if creates := countCalls(client, "CreateRecord"); creates != 0 {
+		t.Errorf("got %d CreateRecord calls, want 0", creates)
+	}
+}
+
+func TestBatchClientCreateRecordMaxBatchSize(t *testing.T) {
+	client := &mock.API{
+		CreateRecordFunc: func(ctx context.Context, req *dns.RecordCreateRequest) (*dns.RecordsResponse, error) {
+			return &dns.RecordsResponse{}, nil
+		},
+	}
+	batchClient := dns.NewBatchClient(client, time.Hour)
+
+	records := make([]dns.Record, dns.MaxRecordBatchSize)
+	for i := range records {
+		records[i] = dns.Record{DnsDomainId: 1, Name: fmt.Sprintf("r%d", i)}
+	}
+	done := make(chan error)
+	go func() {
+		_, err := batchClient.CreateRecord(context.Background(), dns.NewRecordCreateRequest(records...))
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("a full batch was not sent before the window ended")
+	}
+}
+
+func countCalls(client *mock.API, method string) int {
+	count := 0
+	for _, call := range client.Calls {
+		if call.Method == method {
+			count++
+		}
+	}
+	return count
+}
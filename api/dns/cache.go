@@ -0,0 +1,154 @@
+package dns
+
+import (
+	"context"
+	"strconv"
+	"sync"
+)
+
+// CacheClient shares the responses of GetDomainById and GetRecords between the resources of a terraform run, so
+// refreshing or planning the records of a domain looks the domain up and lists its records once instead of once per
+// record. Concurrent calls for the same domain wait for the same request.
+//
+// Writes passing through the CacheClient drop the cached responses of the domain they change, so later calls see
+// the change. Failed calls are not cached. All other calls are passed to the wrapped API.
+type CacheClient struct {
+	API
+
+	mu      sync.Mutex
+	domains map[string]*cachedCall[DomainResponse]
+	records map[int]*cachedCall[RecordsResponse]
+}
+
+// cachedCall is a request shared by the callers asking for the same domain. resp and err are set before done is
+// closed.
+type cachedCall[T any] struct {
+	done chan struct{}
+	resp *T
+	err  error
+}
+
+var _ API = (*CacheClient)(nil)
+
+// NewCacheClient returns a CacheClient wrapping client.
+func NewCacheClient(client API) *CacheClient {
+	return &CacheClient{
+		API:     client,
+		domains: map[string]*cachedCall[DomainResponse]{},
+		records: map[int]*cachedCall[RecordsResponse]{},
+	}
+}
+
+// GetDomainById returns the cached domain or looks it up.
+func (c *CacheClient) GetDomainById(ctx context.Context, domainId string) (*DomainResponse, error) {
+	resp, err := cached(ctx, &c.mu, c.domains, domainId, func(ctx context.Context) (*DomainResponse, error) {
+		return c.API.GetDomainById(ctx, domainId)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// callers get their own copy, so they can't change the cached response
+	domain := *resp
+	domain.DnsDomain.ReplicationMasterIpAddressList = append([]string(nil),
+		resp.DnsDomain.ReplicationMasterIpAddressList...)
+	return &domain, nil
+}
+
+// GetRecords returns the cached records of the domain or lists them.
+func (c *CacheClient) GetRecords(ctx context.Context, domainId int) (*RecordsResponse, error) {
+	resp, err := cached(ctx, &c.mu, c.records, domainId, func(ctx context.Context) (*RecordsResponse, error) {
+		return c.API.GetRecords(ctx, domainId)
+	})
+	if err != nil {
+		return nil, err
+	}
+	records := *resp
+	records.DnsResourceRecordList = make([]*Record, len(resp.DnsResourceRecordList))
+	for i, record := range resp.DnsResourceRecordList {
+		record := *record
+		records.DnsResourceRecordList[i] = &record
+	}
+	return &records, nil
+}
+
+func (c *CacheClient) UpdateDomain(ctx context.Context, domainId string, req *UpdateDomain) (*DomainResponse, error) {
+	defer c.forgetDomain(domainId)
+	return c.API.UpdateDomain(ctx, domainId, req)
+}
+
+func (c *CacheClient) DeleteDomain(ctx context.Context, domainId string) (*DeleteDomainResponse, error) {
+	defer c.forgetDomain(domainId)
+	return c.API.DeleteDomain(ctx, domainId)
+}
+
+func (c *CacheClient) CreateRecord(ctx context.Context, records *RecordCreateRequest) (*RecordsResponse, error) {
+	defer func() {
+		for _, record := range records.DnsResourceRecordList {
+			c.forgetRecords(record.DnsDomainId)
+		}
+	}()
+	return c.API.CreateRecord(ctx, records)
+}
+
+func (c *CacheClient) UpdateRecord(ctx context.Context, domainId int, recordId string, content string, ttl int) (*RecordUpdateResponse, error) {
+	defer c.forgetRecords(domainId)
+	return c.API.UpdateRecord(ctx, domainId, recordId, content, ttl)
+}
+
+func (c *CacheClient) DeleteRecord(ctx context.Context, domainId int, recordId string) (*RecordUpdateResponse, error) {
+	defer c.forgetRecords(domainId)
+	return c.API.DeleteRecord(ctx, domainId, recordId)
+}
+
+// forgetDomain drops the cached domain and its records. The write may have failed after changing the domain, so
+// they are dropped in any case.
+func (c *CacheClient) forgetDomain(domainId string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.domains, domainId)
+	if id, err := strconv.Atoi(domainId); err == nil {
+		delete(c.records, id)
+	}
+}
+
+// forgetRecords drops the cached records of a domain. A listing still running is left to its callers, later calls
+// list the records again.
+func (c *CacheClient) forgetRecords(domainId int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.records, domainId)
+}
+
+// cached returns the response of the call cached under key, sending the call if there is none. The call is not
+// cancelled with the context of the caller sending it, since other callers may wait for it. It ends with the request
+// timeout of the client. Failed calls are dropped from calls.
+func cached[K comparable, T any](ctx context.Context, mu *sync.Mutex, calls map[K]*cachedCall[T], key K,
+	call func(ctx context.Context) (*T, error)) (*T, error) {
+	mu.Lock()
+	c, ok := calls[key]
+	if !ok {
+		c = &cachedCall[T]{done: make(chan struct{})}
+		calls[key] = c
+		go func() {
+			c.resp, c.err = call(context.WithoutCancel(ctx))
+			if c.err != nil {
+				mu.Lock()
+				if calls[key] == c {
+					delete(calls, key)
+				}
+				mu.Unlock()
+			}
+			close(c.done)
+		}()
+	}
+	mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.resp, c.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,216 @@
+package dns_test
+
+import (
+	"context"
+	"errors"
+	"github.com/plusserver/terraform-provider-plusserver/api/dns"
+	"github.com/plusserver/terraform-provider-plusserver/api/dns/mock"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCacheClientGetRecords(t *testing.T) {
+	client := &mock.API{
+		GetRecordsFunc: func(ctx context.Context, domainId int) (*dns.RecordsResponse, error) {
+			return &dns.RecordsResponse{DnsResourceRecordList: []*dns.Record{
+				{DnsResourceRecordId: "10", DnsDomainId: domainId, Name: "www", Type: "A", Content: "10.0.0.1"},
+			}}, nil
+		},
+	}
+	cacheClient := dns.NewCacheClient(client)
+
+	// the records of a plan are refreshed and planned concurrently
+	var wg sync.WaitGroup
+	for i := 0; i < 300; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := cacheClient.GetRecords(context.Background(), 1)
+			if err != nil || len(resp.DnsResourceRecordList) != 1 {
+				t.Errorf("GetRecords() = %v, %v, want the record of the domain", resp, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls := countCalls(client, "GetRecords"); calls != 1 {
+		t.Errorf("got %d GetRecords calls, want 1", calls)
+	}
+
+	// callers get their own copy of the records
+	resp, _ := cacheClient.GetRecords(context.Background(), 1)
+	resp.DnsResourceRecordList[0].Content = "10.0.0.2"
+	resp.DnsResourceRecordList = nil
+	resp, _ = cacheClient.GetRecords(context.Background(), 1)
+	if len(resp.DnsResourceRecordList) != 1 || resp.DnsResourceRecordList[0].Content != "10.0.0.1" {
+		t.Errorf("GetRecords() = %v, want the cached record unchanged", resp.DnsResourceRecordList)
+	}
+
+	if _, err := cacheClient.GetRecords(context.Background(), 2); err != nil {
+		t.Fatal(err)
+	}
+	if calls := countCalls(client, "GetRecords"); calls != 2 {
+		t.Errorf("got %d GetRecords calls, want one per domain", calls)
+	}
+}
+
+func TestCacheClientForgetsChangedDomains(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(c *dns.CacheClient) error
+		// wantDomainCalls and wantRecordCalls count the calls of domain 1 after the write
+		wantDomainCalls int
+		wantRecordCalls int
+	}{
+		{
+			name: "create record",
+			write: func(c *dns.CacheClient) error {
+				_, err := c.CreateRecord(context.Background(), dns.NewRecordCreateRequest(dns.Record{DnsDomainId: 1}))
+				return err
+			},
+			wantDomainCalls: 1,
+			wantRecordCalls: 2,
+		},
+		{
+			name: "update record",
+			write: func(c *dns.CacheClient) error {
+				_, err := c.UpdateRecord(context.Background(), 1, "10", "10.0.0.2", 300)
+				return err
+			},
+			wantDomainCalls: 1,
+			wantRecordCalls: 2,
+		},
+		{
+			name: "failed delete record",
+			write: func(c *dns.CacheClient) error {
+				_, err := c.DeleteRecord(context.Background(), 1, "10")
+				return err
+			},
+			wantDomainCalls: 1,
+			wantRecordCalls: 2,
+		},
+		{
+			name: "record of other domain",
+			write: func(c *dns.CacheClient) error {
+				_, err := c.UpdateRecord(context.Background(), 2, "20", "10.0.0.2", 300)
+				return err
+			},
+			wantDomainCalls: 1,
+			wantRecordCalls: 1,
+		},
+		{
+			name: "update domain",
+			write: func(c *dns.CacheClient) error {
+				_, err := c.UpdateDomain(context.Background(), "1", &dns.UpdateDomain{})
+				return err
+			},
+			wantDomainCalls: 2,
+			wantRecordCalls: 2,
+		},
+		{
+			name: "delete domain",
+			write: func(c *dns.CacheClient) error {
+				_, err := c.DeleteDomain(context.Background(), "1")
+				return err
+			},
+			wantDomainCalls: 2,
+			wantRecordCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &mock.API{
+				GetDomainByIdFunc: func(ctx context.Context, domainId string) (*dns.DomainResponse, error) {
+					return &dns.DomainResponse{DnsDomain: dns.Domain{Name: "example.com"}}, nil
+				},
+				GetRecordsFunc: func(ctx context.Context, domainId int) (*dns.RecordsResponse, error) {
+					return &dns.RecordsResponse{}, nil
+				},
+				CreateRecordFunc: func(ctx context.Context, records *dns.RecordCreateRequest) (*dns.RecordsResponse, error) {
+					return &dns.RecordsResponse{}, nil
+				},
+				UpdateRecordFunc: func(ctx context.Context, domainId int, recordId string, content string, ttl int) (*dns.RecordUpdateResponse, error) {
+					return &dns.RecordUpdateResponse{}, nil
+				},
+				DeleteRecordFunc: func(ctx context.Context, domainId int, recordId string) (*dns.RecordUpdateResponse, error) {
+					return nil, errors.New("bad gateway")
+				},
+				UpdateDomainFunc: func(ctx context.Context, domainId string, req *dns.UpdateDomain) (*dns.DomainResponse, error) {
+					return &dns.DomainResponse{}, nil
+				},
+				DeleteDomainFunc: func(ctx context.Context, domainId string) (*dns.DeleteDomainResponse, error) {
+					return &dns.DeleteDomainResponse{}, nil
+				},
+			}
+			cacheClient := dns.NewCacheClient(client)
+			lookUp := func() {
+				t.Helper()
+				if _, err := cacheClient.GetDomainById(context.Background(), "1"); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := cacheClient.GetRecords(context.Background(), 1); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			lookUp()
+			_ = tt.write(cacheClient)
+			lookUp()
+			if calls := countCalls(client, "GetDomainById"); calls != tt.wantDomainCalls {
+				t.Errorf("got %d GetDomainById calls, want %d", calls, tt.wantDomainCalls)
+			}
+			if calls := countCalls(client, "GetRecords"); calls != tt.wantRecordCalls {
+				t.Errorf("got %d GetRecords calls, want %d", calls, tt.wantRecordCalls)
+			}
+		})
+	}
+}
+
+func TestCacheClientDoesNotCacheErrors(t *testing.T) {
+	fail := true
+	client := &mock.API{
+		GetDomainByIdFunc: func(ctx context.Context, domainId string) (*dns.DomainResponse, error) {
+			if fail {
+				return nil, errors.New("bad gateway")
+			}
+			return &dns.DomainResponse{DnsDomain: dns.Domain{Name: "example.com"}}, nil
+		},
+	}
+	cacheClient := dns.NewCacheClient(client)
+
+	if _, err := cacheClient.GetDomainById(context.Background(), "1"); err == nil {
+		t.Fatal("GetDomainById() succeeded, want the error of the api")
+	}
+	fail = false
+	resp, err := cacheClient.GetDomainById(context.Background(), "1")
+	if err != nil || resp.DnsDomain.Name != "example.com" {
+		t.Errorf("GetDomainById() = %v, %v, want the domain", resp, err)
+	}
+}
+
+func TestCacheClientContextDone(t *testing.T) {
+	release := make(chan struct{})
+	client := &mock.API{
+		GetRecordsFunc: func(ctx context.Context, domainId int) (*dns.RecordsResponse, error) {
+			<-release
+			return &dns.RecordsResponse{}, nil
+		},
+	}
+	cacheClient := dns.NewCacheClient(client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := cacheClient.GetRecords(ctx, 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// the listing goes on for the callers still waiting for it
+	close(release)
+	if _, err := cacheClient.GetRecords(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	if calls := countCalls(client, "GetRecords"); calls != 1 {
+		t.Errorf("got %d GetRecords calls, want 1", calls)
+	}
+}
@@ -3,6 +3,7 @@ package dns
 import (
 	"bytes"
 	"context"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/plusserver/terraform-provider-plusserver/api"
 	"io"
 )
@@ -11,8 +12,8 @@ type Client struct {
 	api.Client
 }
 
-func NewDNSClient(credentials *api.OAuthConfig, baseURL string) (*Client, error) {
-	client, err := api.NewHTTPClient(credentials, "dnsEntityService", baseURL)
+func NewDNSClient(ctx context.Context, credentials *api.OAuthConfig, httpConfig *api.HTTPConfig, baseURL string) (*Client, error) {
+	client, err := api.NewHTTPClient(ctx, credentials, httpConfig, "dnsEntityService", baseURL)
 	if err != nil {
 		return nil, err
 	}
@@ -23,4 +24,11 @@ func (c *Client) MakeRequest(ctx context.Context, method string, endpoint string
 	return c.Client.MakeRequest(ctx, method, endpoint, body)
 }
 
-
+// logContext sets fields on the root logger and registers the dns subsystem, so the fields are
+// attached to the dns log entries as well as to the requests logged by the api subsystem.
+func logContext(ctx context.Context, fields map[string]interface{}) context.Context {
+	for key, value := range fields {
+		ctx = tflog.SetField(ctx, key, value)
+	}
+	return api.WithLogSubsystem(ctx, api.SubsystemDNS)
+}
@@ -1,12 +1,10 @@
 package dns
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"log"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/plusserver/terraform-provider-plusserver/api"
 	"net/http"
 )
 
@@ -18,203 +16,114 @@ type SearchDomain struct {
 	DnsDomainSearchList []DomainSearchList `json:"dnsDomainSearchList"`
 }
 
+// NewSearchDomain returns a search for domains with one of the given names.
+func NewSearchDomain(names ...string) *SearchDomain {
+	return &SearchDomain{DnsDomainSearchList: []DomainSearchList{{NameList: names}}}
+}
+
+// ResultSetProperties is part of every response of the DNS entity service.
+type ResultSetProperties struct {
+}
+
+// Domain is a domain as returned by the DNS entity service.
+type Domain struct {
+	DnsDomainId                    int                `json:"dnsDomainId"`
+	Name                           string             `json:"name"`
+	UnicodeName                    string             `json:"unicodeName"`
+	CompanyId                      string             `json:"companyId"`
+	ContractId                     string             `json:"contractId"`
+	CreateDateTime                 api.PlusServerTime `json:"createDateTime"`
+	DnsNameserverPairName          string             `json:"dnsNameserverPairName"`
+	Protected                      bool               `json:"protected"`
+	ReplicationType                string             `json:"replicationType"`
+	ReplicationMasterIpAddressList []string           `json:"replicationMasterIpAddressList"`
+}
+
+// DomainResponse is returned when a single domain is read, created or updated.
+type DomainResponse struct {
+	DnsDomain           Domain              `json:"dnsDomain"`
+	ResultSetProperties ResultSetProperties `json:"resultSetProperties"`
+}
+
 type SearchDomainResponse struct {
-	DnsDomainList []DomainListResponse `json:"dnsDomainList"`
-}
-
-type DomainGetResponse struct {
-	DnsDomain struct {
-		CompanyId                      string   `json:"companyId"`
-		ContractId                     string   `json:"contractId"`
-		CreateDateTime                 string   `json:"createDateTime"`
-		DnsDomainId                    int      `json:"dnsDomainId"`
-		DnsNameserverPairName          string   `json:"dnsNameserverPairName"`
-		Name                           string   `json:"name"`
-		Protected                      bool     `json:"protected"`
-		ReplicationMasterIpAddressList []string `json:"replicationMasterIpAddressList"`
-		ReplicationType                string   `json:"replicationType"`
-		UnicodeName                    string   `json:"unicodeName"`
-	} `json:"dnsDomain"`
-	ResultSetProperties struct {
-	} `json:"resultSetProperties"`
-}
-
-type DomainListResponse struct {
+	DnsDomainList []Domain `json:"dnsDomainList"`
+}
+
+type DeleteDomainResponse struct {
+	ResultSetProperties ResultSetProperties `json:"resultSetProperties"`
+}
+
+// DomainCreate holds the attributes of a new domain.
+type DomainCreate struct {
 	UnicodeName                    string   `json:"unicodeName"`
-	Name                           string   `json:"name"`
-	DnsNameserverPairName          string   `json:"dnsNameserverPairName"`
 	CompanyId                      string   `json:"companyId"`
-	DnsDomainId                    int      `json:"dnsDomainId"`
+	DnsNameserverPairName          string   `json:"dnsNameserverPairName"`
 	Protected                      bool     `json:"protected"`
 	ReplicationType                string   `json:"replicationType"`
 	ReplicationMasterIpAddressList []string `json:"replicationMasterIpAddressList"`
-	CreateDateTime                 string   `json:"createDateTime"`
 	ContractId                     string   `json:"contractId"`
 }
 
-type DeleteDomainResponse struct {
-	ResultSetProperties struct {
-	} `json:"resultSetProperties"`
+type CreateDomain struct {
+	DnsDomain DomainCreate `json:"dnsDomain"`
 }
 
-type UpdateDomain struct {
-	DnsDomain struct {
-		ReplicationMasterIpAddressList []string `json:"replicationMasterIpAddressList"`
-		Protected                      bool     `json:"protected"`
-		CompanyId                      string   `json:"companyId"`
-		ContractId                     string   `json:"contractId"`
-	} `json:"dnsDomain"`
-}
-
-type UpdateDomainResponse struct {
-	DnsDomain struct {
-		CompanyId                      string        `json:"companyId"`
-		ContractId                     string        `json:"contractId"`
-		CreateDateTime                 string        `json:"createDateTime"`
-		DnsDomainId                    int           `json:"dnsDomainId"`
-		DnsNameserverPairName          string        `json:"dnsNameserverPairName"`
-		Name                           string        `json:"name"`
-		Protected                      bool          `json:"protected"`
-		ReplicationMasterIpAddressList []interface{} `json:"replicationMasterIpAddressList"`
-		ReplicationType                string        `json:"replicationType"`
-		UnicodeName                    string        `json:"unicodeName"`
-	} `json:"dnsDomain"`
-	ResultSetProperties struct {
-	} `json:"resultSetProperties"`
+// NewCreateDomain wraps domain into the request of CreateDomain.
+func NewCreateDomain(domain DomainCreate) *CreateDomain {
+	return &CreateDomain{DnsDomain: domain}
 }
 
-type CreateDomain struct {
-	DnsDomain struct {
-		UnicodeName                    string   `json:"unicodeName"`
-		CompanyId                      string   `json:"companyId"`
-		DnsNameserverPairName          string   `json:"dnsNameserverPairName"`
-		Protected                      bool     `json:"protected"`
-		ReplicationType                string   `json:"replicationType"`
-		ReplicationMasterIpAddressList []string `json:"replicationMasterIpAddressList"`
-		ContractId                     string   `json:"contractId"`
-	} `json:"dnsDomain"`
-}
-
-type CreateDomainResponse struct {
-	DnsDomain struct {
-		UnicodeName                    string   `json:"unicodeName"`
-		Name                           string   `json:"name"`
-		DnsNameserverPairName          string   `json:"dnsNameserverPairName"`
-		CompanyId                      string   `json:"companyId"`
-		DnsDomainId                    int      `json:"dnsDomainId"`
-		Protected                      bool     `json:"protected"`
-		ReplicationType                string   `json:"replicationType"`
-		ReplicationMasterIpAddressList []string `json:"replicationMasterIpAddressList"`
-		CreateDateTime                 string   `json:"createDateTime"`
-		ContractId                     string   `json:"contractId"`
-	} `json:"dnsDomain"`
-}
-
-func debugPrint(body []byte) {
-	var prettyJSON bytes.Buffer
-	err := json.Indent(&prettyJSON, body, "", "\t")
-	if err != nil {
-		log.Println("[ERROR] JSON parse error: ", err)
-		return
-	}
-	log.Println("[INFO] payload:", string(prettyJSON.Bytes()))
+// DomainUpdate holds the attributes of a domain which can be updated.
+type DomainUpdate struct {
+	ReplicationMasterIpAddressList []string `json:"replicationMasterIpAddressList"`
+	Protected                      bool     `json:"protected"`
+	CompanyId                      string   `json:"companyId"`
+	ContractId                     string   `json:"contractId"`
+}
 
+type UpdateDomain struct {
+	DnsDomain DomainUpdate `json:"dnsDomain"`
+}
+
+// NewUpdateDomain wraps domain into the request of UpdateDomain.
+func NewUpdateDomain(domain DomainUpdate) *UpdateDomain {
+	return &UpdateDomain{DnsDomain: domain}
 }
 
 func (c *Client) SearchDomains(ctx context.Context, domain string) (*SearchDomainResponse, error) {
-	data, err := json.Marshal(&SearchDomain{DnsDomainSearchList: []DomainSearchList{{NameList: []string{domain}}}})
-	debugPrint(data)
-	if err != nil {
-		return nil, err
-	}
-	body, err := c.MakeRequest(ctx, http.MethodPost, "dnsDomains/search", bytes.NewBuffer(data))
-	if err != nil {
-		return nil, err
-	}
-	bodyBytes, err := ioutil.ReadAll(body)
-	if err != nil {
-		return nil, err
-	}
-	var result SearchDomainResponse
-	err = json.Unmarshal(bodyBytes, &result)
-	if err != nil {
-		return nil, err
-	}
-	return &result, nil
+	ctx = logContext(ctx, map[string]interface{}{"domain_name": domain})
+	tflog.SubsystemDebug(ctx, api.SubsystemDNS, "searching domains")
+	return api.Do[SearchDomain, SearchDomainResponse](ctx, &c.Client, http.MethodPost, "dnsDomains/search",
+		NewSearchDomain(domain))
 }
 
-func (c *Client) GetDomainById(ctx context.Context, domainId string) (*DomainGetResponse, error) {
-	body, err := c.MakeRequest(ctx, http.MethodGet, fmt.Sprintf("dnsDomains/%s", domainId), &bytes.Buffer{})
-	if err != nil {
-		return nil, err
-	}
-	bodyBytes, err := ioutil.ReadAll(body)
-	if err != nil {
-		return nil, err
-	}
-	var result DomainGetResponse
-	err = json.Unmarshal(bodyBytes, &result)
-	if err != nil {
-		return nil, err
-	}
-	return &result, nil
+func (c *Client) GetDomainById(ctx context.Context, domainId string) (*DomainResponse, error) {
+	ctx = logContext(ctx, map[string]interface{}{"domain_id": domainId})
+	tflog.SubsystemDebug(ctx, api.SubsystemDNS, "reading domain")
+	return api.Do[api.Empty, DomainResponse](ctx, &c.Client, http.MethodGet, fmt.Sprintf("dnsDomains/%s", domainId), nil)
 }
 
 func (c *Client) DeleteDomain(ctx context.Context, domainId string) (*DeleteDomainResponse, error) {
-	body, err := c.MakeRequest(ctx, http.MethodDelete, fmt.Sprintf("dnsDomains/%s", domainId), &bytes.Buffer{})
-	if err != nil {
-		return nil, err
-	}
-	bodyBytes, err := ioutil.ReadAll(body)
-	if err != nil {
-		return nil, err
-	}
-	var result DeleteDomainResponse
-	err = json.Unmarshal(bodyBytes, &result)
-	if err != nil {
-		return nil, err
-	}
-	return &result, nil
+	ctx = logContext(ctx, map[string]interface{}{"domain_id": domainId})
+	tflog.SubsystemDebug(ctx, api.SubsystemDNS, "deleting domain")
+	return api.Do[api.Empty, DeleteDomainResponse](ctx, &c.Client, http.MethodDelete, fmt.Sprintf("dnsDomains/%s", domainId), nil)
 }
 
-func (c *Client) UpdateDomain(ctx context.Context, domainId string, req *UpdateDomain) (*UpdateDomainResponse, error) {
-	data, err := json.Marshal(req)
-	if err != nil {
-		return nil, err
-	}
-	body, err := c.MakeRequest(ctx, http.MethodPut, fmt.Sprintf("dnsDomains/%s", domainId), bytes.NewBuffer(data))
-	if err != nil {
-		return nil, err
-	}
-	bodyBytes, err := ioutil.ReadAll(body)
-	if err != nil {
-		return nil, err
-	}
-	var result UpdateDomainResponse
-	err = json.Unmarshal(bodyBytes, &result)
-	if err != nil {
-		return nil, err
-	}
-	return &result, nil
+func (c *Client) UpdateDomain(ctx context.Context, domainId string, req *UpdateDomain) (*DomainResponse, error) {
+	ctx = logContext(ctx, map[string]interface{}{"domain_id": domainId})
+	tflog.SubsystemDebug(ctx, api.SubsystemDNS, "updating domain")
+	return api.Do[UpdateDomain, DomainResponse](ctx, &c.Client, http.MethodPut, fmt.Sprintf("dnsDomains/%s", domainId), req)
 }
 
-func (c *Client) CreateDomain(ctx context.Context, req *CreateDomain) (*CreateDomainResponse, error) {
-	data, err := json.Marshal(req)
-	if err != nil {
-		return nil, err
-	}
-	body, err := c.MakeRequest(ctx, http.MethodPost, "dnsDomains", bytes.NewBuffer(data))
-	if err != nil {
-		return nil, err
-	}
-	bodyBytes, err := ioutil.ReadAll(body)
-	if err != nil {
-		return nil, err
-	}
-	var result CreateDomainResponse
-	err = json.Unmarshal(bodyBytes, &result)
+func (c *Client) CreateDomain(ctx context.Context, req *CreateDomain) (*DomainResponse, error) {
+	ctx = logContext(ctx, map[string]interface{}{"domain_name": req.DnsDomain.UnicodeName})
+	tflog.SubsystemDebug(ctx, api.SubsystemDNS, "creating domain")
+	result, err := api.Do[CreateDomain, DomainResponse](ctx, &c.Client, http.MethodPost, "dnsDomains", req)
 	if err != nil {
 		return nil, err
 	}
-	return &result, nil
+	tflog.SubsystemInfo(ctx, api.SubsystemDNS, "created domain", map[string]interface{}{
+		"domain_id": result.DnsDomain.DnsDomainId,
+	})
+	return result, nil
 }
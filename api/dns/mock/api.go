@@ -0,0 +1,116 @@
+// Package mock provides an implementation of dns.API for testing code using the DNS client without HTTP.
+package mock
+
+import (
+	"context"
+	"fmt"
+	"github.com/plusserver/terraform-provider-plusserver/api/dns"
+	"sync"
+)
+
+// API implements dns.API by calling the function of the same name. Calls of methods without function fail.
+// All calls are recorded in Calls.
+type API struct {
+	SearchDomainsFunc func(ctx context.Context, domain string) (*dns.SearchDomainResponse, error)
+	GetDomainByIdFunc func(ctx context.Context, domainId string) (*dns.DomainResponse, error)
+	CreateDomainFunc  func(ctx context.Context, req *dns.CreateDomain) (*dns.DomainResponse, error)
+	UpdateDomainFunc  func(ctx context.Context, domainId string, req *dns.UpdateDomain) (*dns.DomainResponse, error)
+	DeleteDomainFunc  func(ctx context.Context, domainId string) (*dns.DeleteDomainResponse, error)
+	GetRecordsFunc    func(ctx context.Context, domainId int) (*dns.RecordsResponse, error)
+	CreateRecordFunc  func(ctx context.Context, records *dns.RecordCreateRequest) (*dns.RecordsResponse, error)
+	UpdateRecordFunc  func(ctx context.Context, domainId int, recordId string, content string, ttl int) (*dns.RecordUpdateResponse, error)
+	DeleteRecordFunc  func(ctx context.Context, domainId int, recordId string) (*dns.RecordUpdateResponse, error)
+
+	mu    sync.Mutex
+	Calls []Call
+}
+
+// Call is a recorded call of API.
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+var _ dns.API = (*API)(nil)
+
+func (m *API) record(method string, args ...interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Calls = append(m.Calls, Call{Method: method, Args: args})
+}
+
+func notImplemented(method string) error {
+	return fmt.Errorf("mock: %s is not implemented", method)
+}
+
+func (m *API) SearchDomains(ctx context.Context, domain string) (*dns.SearchDomainResponse, error) {
+	m.record("SearchDomains", domain)
+	if m.SearchDomainsFunc == nil {
+		return nil, notImplemented("SearchDomains")
+	}
+	return m.SearchDomainsFunc(ctx, domain)
+}
+
+func (m *API) GetDomainById(ctx context.Context, domainId string) (*dns.DomainResponse, error) {
+	m.record("GetDomainById", domainId)
+	if m.GetDomainByIdFunc == nil {
+		return nil, notImplemented("GetDomainById")
+	}
+	return m.GetDomainByIdFunc(ctx, domainId)
+}
+
+func (m *API) CreateDomain(ctx context.Context, req *dns.CreateDomain) (*dns.DomainResponse, error) {
+	m.record("CreateDomain", req)
+	if m.CreateDomainFunc == nil {
+		return nil, notImplemented("CreateDomain")
+	}
+	return m.CreateDomainFunc(ctx, req)
+}
+
+func (m *API) UpdateDomain(ctx context.Context, domainId string, req *dns.UpdateDomain) (*dns.DomainResponse, error) {
+	m.record("UpdateDomain", domainId, req)
+	if m.UpdateDomainFunc == nil {
+		return nil, notImplemented("UpdateDomain")
+	}
+	return m.UpdateDomainFunc(ctx, domainId, req)
+}
+
+func (m *API) DeleteDomain(ctx context.Context, domainId string) (*dns.DeleteDomainResponse, error) {
+	m.record("DeleteDomain", domainId)
+	if m.DeleteDomainFunc == nil {
+		return nil, notImplemented("DeleteDomain")
+	}
+	return m.DeleteDomainFunc(ctx, domainId)
+}
+
+func (m *API) GetRecords(ctx context.Context, domainId int) (*dns.RecordsResponse, error) {
+	m.record("GetRecords", domainId)
+	if m.GetRecordsFunc == nil {
+		return nil, notImplemented("GetRecords")
+	}
+	return m.GetRecordsFunc(ctx, domainId)
+}
+
+func (m *API) CreateRecord(ctx context.Context, records *dns.RecordCreateRequest) (*dns.RecordsResponse, error) {
+	m.record("CreateRecord", records)
+	if m.CreateRecordFunc == nil {
+		return nil, notImplemented("CreateRecord")
+	}
+	return m.CreateRecordFunc(ctx, records)
+}
+
+func (m *API) UpdateRecord(ctx context.Context, domainId int, recordId string, content string, ttl int) (*dns.RecordUpdateResponse, error) {
+	m.record("UpdateRecord", domainId, recordId, content, ttl)
+	if m.UpdateRecordFunc == nil {
+		return nil, notImplemented("UpdateRecord")
+	}
+	return m.UpdateRecordFunc(ctx, domainId, recordId, content, ttl)
+}
+
+func (m *API) DeleteRecord(ctx context.Context, domainId int, recordId string) (*dns.RecordUpdateResponse, error) {
+	m.record("DeleteRecord", domainId, recordId)
+	if m.DeleteRecordFunc == nil {
+		return nil, notImplemented("DeleteRecord")
+	}
+	return m.DeleteRecordFunc(ctx, domainId, recordId)
+}
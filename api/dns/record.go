@@ -1,130 +1,80 @@
 package dns
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/plusserver/terraform-provider-plusserver/api"
 	"net/http"
 )
 
-type RecordsResponseEntry struct {
-	Content             string `json:"content"`
+// Record is a resource record of a domain. DnsResourceRecordId is assigned by the api and omitted when
+// records are created.
+type Record struct {
+	DnsResourceRecordId string `json:"dnsResourceRecordId,omitempty"`
 	DnsDomainId         int    `json:"dnsDomainId"`
-	DnsResourceRecordId string `json:"dnsResourceRecordId"`
 	Name                string `json:"name"`
-	Ttl                 int    `json:"ttl"`
 	Type                string `json:"type"`
+	Content             string `json:"content"`
+	Ttl                 int    `json:"ttl"`
 }
 
 type RecordsResponse struct {
-	DnsResourceRecordList []*RecordsResponseEntry `json:"dnsResourceRecordList"`
-	ResultSetProperties   struct {
-	} `json:"resultSetProperties"`
+	DnsResourceRecordList []*Record           `json:"dnsResourceRecordList"`
+	ResultSetProperties   ResultSetProperties `json:"resultSetProperties"`
+}
+
+// RecordContent holds the attributes of a record which can be updated.
+type RecordContent struct {
+	Content string `json:"content"`
+	Ttl     int    `json:"ttl"`
 }
 
 type RecordUpdate struct {
-	DnsResourceRecord struct {
-		Content string `json:"content"`
-		Ttl     int    `json:"ttl"`
-	} `json:"dnsResourceRecord"`
+	DnsResourceRecord RecordContent `json:"dnsResourceRecord"`
+}
+
+// NewRecordUpdate returns the request of UpdateRecord.
+func NewRecordUpdate(content string, ttl int) *RecordUpdate {
+	return &RecordUpdate{DnsResourceRecord: RecordContent{Content: content, Ttl: ttl}}
 }
 
 type RecordUpdateResponse struct {
-	ResultSetProperties struct {
-	} `json:"resultSetProperties"`
+	ResultSetProperties ResultSetProperties `json:"resultSetProperties"`
 }
 
 type RecordCreateRequest struct {
-	DnsResourceRecordList []struct {
-		Content     string `json:"content"`
-		DnsDomainId int    `json:"dnsDomainId"`
-		Type        string `json:"type"`
-		Name        string `json:"name"`
-		Ttl         int    `json:"ttl"`
-	} `json:"dnsResourceRecordList"`
+	DnsResourceRecordList []Record `json:"dnsResourceRecordList"`
+}
+
+// NewRecordCreateRequest returns the request of CreateRecord creating all records at once.
+func NewRecordCreateRequest(records ...Record) *RecordCreateRequest {
+	return &RecordCreateRequest{DnsResourceRecordList: records}
 }
 
 func (c *Client) GetRecords(ctx context.Context, domainId int) (*RecordsResponse, error) {
-	body, err := c.MakeRequest(ctx, http.MethodGet, fmt.Sprintf("dnsDomains/%d/dnsResourceRecords", domainId), &bytes.Buffer{})
-	if err != nil {
-		return nil, err
-	}
-	bodyBytes, err := ioutil.ReadAll(body)
-	if err != nil {
-		return nil, err
-	}
-	var result RecordsResponse
-	err = json.Unmarshal(bodyBytes, &result)
-	if err != nil {
-		return nil, err
-	}
-	return &result, nil
+	ctx = logContext(ctx, map[string]interface{}{"domain_id": domainId})
+	tflog.SubsystemDebug(ctx, api.SubsystemDNS, "reading records")
+	return api.Do[api.Empty, RecordsResponse](ctx, &c.Client, http.MethodGet, fmt.Sprintf("dnsDomains/%d/dnsResourceRecords", domainId), nil)
 }
 
-func (c *Client) CreateRecord(ctx context.Context, records *RecordCreateRequest) (*RecordUpdateResponse, error){
-	data, err := json.Marshal(records)
-	if err != nil {
-		return nil, err
-	}
-	body, err := c.MakeRequest(ctx, http.MethodPost, "/dnsResourceRecords", bytes.NewBuffer(data))
-	if err != nil {
-		return nil, err
-	}
-	bodyBytes, err := ioutil.ReadAll(body)
-	if err != nil {
-		return nil, err
+func (c *Client) CreateRecord(ctx context.Context, records *RecordCreateRequest) (*RecordsResponse, error) {
+	for _, record := range records.DnsResourceRecordList {
+		tflog.SubsystemDebug(logContext(ctx, map[string]interface{}{"domain_id": record.DnsDomainId}), api.SubsystemDNS,
+			"creating record", map[string]interface{}{"name": record.Name, "type": record.Type})
 	}
-	var result RecordsResponse
-	err = json.Unmarshal(bodyBytes, &result)
-	if err != nil {
-		return nil, err
-	}
-
-	return nil, nil
+	return api.Do[RecordCreateRequest, RecordsResponse](ctx, &c.Client, http.MethodPost, "/dnsResourceRecords", records)
 }
 
 func (c *Client) UpdateRecord(ctx context.Context, domainId int, recordId string, content string, ttl int) (*RecordUpdateResponse, error) {
-	data, err := json.Marshal(&RecordUpdate{DnsResourceRecord: struct {
-		Content string `json:"content"`
-		Ttl     int    `json:"ttl"`
-	}(struct {
-		Content string
-		Ttl     int
-	}{Content: content, Ttl: ttl})})
-	if err != nil {
-		return nil, err
-	}
-	body, err := c.MakeRequest(ctx, http.MethodPut, fmt.Sprintf("dnsResourceRecords/%d/%s", domainId, recordId), bytes.NewBuffer(data))
-	if err != nil {
-		return nil, err
-	}
-	bodyBytes, err := ioutil.ReadAll(body)
-	if err != nil {
-		return nil, err
-	}
-	var result RecordUpdateResponse
-	err = json.Unmarshal(bodyBytes, &result)
-	if err != nil {
-		return nil, err
-	}
-	return &result, nil
+	ctx = logContext(ctx, map[string]interface{}{"domain_id": domainId, "record_id": recordId})
+	tflog.SubsystemDebug(ctx, api.SubsystemDNS, "updating record")
+	return api.Do[RecordUpdate, RecordUpdateResponse](ctx, &c.Client, http.MethodPut, fmt.Sprintf("dnsResourceRecords/%d/%s", domainId, recordId),
+		NewRecordUpdate(content, ttl))
 }
 
 func (c *Client) DeleteRecord(ctx context.Context, domainId int, recordId string) (*RecordUpdateResponse, error) {
-	body, err := c.MakeRequest(ctx, http.MethodDelete, fmt.Sprintf("dnsResourceRecords/%d/%s", domainId, recordId), &bytes.Buffer{})
-	if err != nil {
-		return nil, err
-	}
-	bodyBytes, err := ioutil.ReadAll(body)
-	if err != nil {
-		return nil, err
-	}
-	var result RecordUpdateResponse
-	err = json.Unmarshal(bodyBytes, &result)
-	if err != nil {
-		return nil, err
-	}
-	return &result, nil
+	ctx = logContext(ctx, map[string]interface{}{"domain_id": domainId, "record_id": recordId})
+	tflog.SubsystemDebug(ctx, api.SubsystemDNS, "deleting record")
+	return api.Do[api.Empty, RecordUpdateResponse](ctx, &c.Client, http.MethodDelete, fmt.Sprintf("dnsResourceRecords/%d/%s", domainId, recordId), nil)
 }
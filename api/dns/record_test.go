@@ -0,0 +1,49 @@
+package dns
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRecordRequestsJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		req  interface{}
+		want string
+	}{
+		{
+			name: "create",
+			req: NewRecordCreateRequest(
+				Record{DnsDomainId: 1, Name: "www", Type: "A", Content: "192.0.2.1", Ttl: 300},
+				Record{DnsDomainId: 1, Name: "@", Type: "MX", Content: "10 mx.example.com", Ttl: 3600},
+			),
+			want: `{"dnsResourceRecordList":[` +
+				`{"dnsDomainId":1,"name":"www","type":"A","content":"192.0.2.1","ttl":300},` +
+				`{"dnsDomainId":1,"name":"@","type":"MX","content":"10 mx.example.com","ttl":3600}]}`,
+		},
+		{
+			name: "update",
+			req:  NewRecordUpdate("192.0.2.2", 600),
+			want: `{"dnsResourceRecord":{"content":"192.0.2.2","ttl":600}}`,
+		},
+		{
+			name: "create domain",
+			req: NewCreateDomain(DomainCreate{UnicodeName: "example.com", CompanyId: "1", ContractId: "2",
+				DnsNameserverPairName: "default", ReplicationType: "NONE"}),
+			want: `{"dnsDomain":{"unicodeName":"example.com","companyId":"1","dnsNameserverPairName":"default",` +
+				`"protected":false,"replicationType":"NONE","replicationMasterIpAddressList":null,"contractId":"2"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("json = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
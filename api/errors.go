@@ -0,0 +1,30 @@
+package api
+
+import (
+	"fmt"
+)
+
+// RequestError is returned by MakeRequest if a request could not be sent or the api answered with a status
+// other than 200. It carries the ids sent with the request so a failed call can be traced in the gateway logs.
+type RequestError struct {
+	Method        string
+	URL           string
+	StatusCode    int
+	Body          string
+	MessageId     string
+	TransactionId string
+	// Err is the transport error if no response was received
+	Err error
+}
+
+func (e *RequestError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s %s failed: %s (transaction id %s)", e.Method, e.URL, e.Err, e.TransactionId)
+	}
+	return fmt.Sprintf("got a non 200 status code: %v - %s URL: %s (transaction id %s)", e.StatusCode, e.Body, e.URL,
+		e.TransactionId)
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,249 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const redacted = "REDACTED"
+
+// maxLoggedBodySize limits the part of a request or response body held in memory for the log. Larger bodies are
+// streamed through unchanged and are not logged.
+const maxLoggedBodySize = 64 << 10
+
+// sensitiveHeaders are replaced before a request or response is logged.
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+// sensitiveFields are replaced in JSON bodies, form bodies and query strings before they are logged.
+var sensitiveFields = map[string]bool{
+	"access_token":  true,
+	"refresh_token": true,
+	"id_token":      true,
+	"token":         true,
+	"password":      true,
+	"client_secret": true,
+}
+
+// Logging subsystems of the provider. Each of them can be filtered with TF_LOG_PROVIDER_PLUSSERVER_<SUBSYSTEM>.
+const (
+	SubsystemAPI  = "api"
+	SubsystemDNS  = "dns"
+	SubsystemAuth = "auth"
+)
+
+type logSubsystemKey struct{}
+
+// WithLogSubsystem registers the tflog subsystem on ctx. The subsystem inherits all fields set on the root
+// logger so far and is used by the LoggingTransport for requests made with the returned context.
+func WithLogSubsystem(ctx context.Context, subsystem string) context.Context {
+	ctx = tflog.NewSubsystem(ctx, subsystem, tflog.WithRootFields(),
+		tflog.WithLevelFromEnv("TF_LOG_PROVIDER_PLUSSERVER_"+strings.ToUpper(subsystem)))
+	return context.WithValue(ctx, logSubsystemKey{}, subsystem)
+}
+
+func logSubsystem(ctx context.Context) string {
+	if subsystem, ok := ctx.Value(logSubsystemKey{}).(string); ok {
+		return subsystem
+	}
+	return SubsystemAPI
+}
+
+// debugEnabled reports whether debug entries of the subsystem are written. The provider loggers take their level
+// from TF_LOG_PROVIDER_PLUSSERVER_<SUBSYSTEM> and TF_LOG_PROVIDER_PLUSSERVER, an unset or unknown level is inherited
+// from the next one. Terraform filters the provider logs with TF_LOG_PROVIDER and TF_LOG, which count unknown levels
+// and JSON as TRACE.
+func debugEnabled(subsystem string) bool {
+	for _, env := range []string{"TF_LOG_PROVIDER_PLUSSERVER_" + strings.ToUpper(subsystem), "TF_LOG_PROVIDER_PLUSSERVER"} {
+		switch strings.ToLower(strings.TrimSpace(os.Getenv(env))) {
+		case "trace", "debug":
+			return true
+		case "info", "warn", "error", "off":
+			return false
+		}
+	}
+	for _, env := range []string{"TF_LOG_PROVIDER", "TF_LOG"} {
+		switch strings.ToUpper(os.Getenv(env)) {
+		case "":
+			continue
+		case "INFO", "WARN", "ERROR", "OFF":
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// LoggingTransport logs method, url, transaction ids, status, latency and bodies of every request
+// passing through it to the tflog subsystem of the request context. Credentials are redacted before
+// anything is written to the log.
+type LoggingTransport struct {
+	Transport http.RoundTripper
+}
+
+// NewLoggingTransport wraps next with a LoggingTransport.
+func NewLoggingTransport(next http.RoundTripper) http.RoundTripper {
+	return &LoggingTransport{Transport: next}
+}
+
+func (t *LoggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	ctx := req.Context()
+	subsystem := logSubsystem(ctx)
+	if !debugEnabled(subsystem) {
+		return transport.RoundTrip(req)
+	}
+
+	reqBody, err := drainBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+	tflog.SubsystemDebug(ctx, subsystem, "HTTP request", map[string]interface{}{
+		"method":         req.Method,
+		"url":            redactURL(req.URL),
+		"message_id":     req.Header.Get("X-Message-Id"),
+		"transaction_id": req.Header.Get("X-Transaction-Id"),
+		"headers":        formatHeaders(req.Header),
+		"body":           formatBody(req.Header.Get("Content-Type"), reqBody),
+	})
+
+	start := time.Now()
+	resp, err := transport.RoundTrip(req)
+	latency := time.Since(start)
+	if err != nil {
+		tflog.SubsystemDebug(ctx, subsystem, "HTTP request failed", map[string]interface{}{
+			"method":         req.Method,
+			"url":            redactURL(req.URL),
+			"transaction_id": req.Header.Get("X-Transaction-Id"),
+			"latency":        latency.String(),
+			"error":          err.Error(),
+		})
+		return nil, err
+	}
+
+	respBody, err := drainBody(&resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	tflog.SubsystemDebug(ctx, subsystem, "HTTP response", map[string]interface{}{
+		"method":         req.Method,
+		"url":            redactURL(req.URL),
+		"transaction_id": req.Header.Get("X-Transaction-Id"),
+		"status":         resp.StatusCode,
+		"latency":        latency.String(),
+		"headers":        formatHeaders(resp.Header),
+		"body":           formatBody(resp.Header.Get("Content-Type"), respBody),
+	})
+	return resp, nil
+}
+
+// drainBody reads up to maxLoggedBodySize+1 bytes of the body and puts them back in front of the rest, so the body
+// can still be consumed completely. The returned data is longer than maxLoggedBodySize if the body was cut.
+func drainBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(*body, maxLoggedBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	*body = &prefixedBody{Reader: io.MultiReader(bytes.NewReader(data), *body), Closer: *body}
+	return data, nil
+}
+
+// prefixedBody reads the drained prefix and the rest of a body and closes the original body.
+type prefixedBody struct {
+	io.Reader
+	io.Closer
+}
+
+func formatHeaders(header http.Header) map[string]string {
+	headers := make(map[string]string, len(header))
+	for key, values := range header {
+		value := strings.Join(values, ", ")
+		if sensitiveHeaders[http.CanonicalHeaderKey(key)] {
+			value = redacted
+		}
+		headers[key] = value
+	}
+	return headers
+}
+
+func formatBody(contentType string, body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	if len(body) > maxLoggedBodySize {
+		// a cut body can't be parsed for redaction, so nothing of it is logged
+		return fmt.Sprintf("(body larger than %d bytes not logged)", maxLoggedBodySize)
+	}
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		values, err := url.ParseQuery(string(body))
+		if err == nil {
+			return redactValues(values).Encode()
+		}
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return string(body)
+	}
+	pretty, err := json.MarshalIndent(redactJSON(data), "", "\t")
+	if err != nil {
+		return string(body)
+	}
+	return string(pretty)
+}
+
+func redactURL(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.String()
+	}
+	redactedURL := *u
+	redactedURL.RawQuery = redactValues(u.Query()).Encode()
+	return redactedURL.String()
+}
+
+func redactValues(values url.Values) url.Values {
+	for key := range values {
+		if sensitiveFields[strings.ToLower(key)] {
+			values[key] = []string{redacted}
+		}
+	}
+	return values
+}
+
+func redactJSON(data interface{}) interface{} {
+	switch value := data.(type) {
+	case map[string]interface{}:
+		for key, elem := range value {
+			if sensitiveFields[strings.ToLower(key)] {
+				value[key] = redacted
+			} else {
+				value[key] = redactJSON(elem)
+			}
+		}
+	case []interface{}:
+		for i, elem := range value {
+			value[i] = redactJSON(elem)
+		}
+	}
+	return data
+}
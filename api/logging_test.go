@@ -0,0 +1,199 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFormatBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+	}{
+		{name: "empty", contentType: "application/json", body: "", want: ""},
+		{
+			name:        "JSON",
+			contentType: "application/json",
+			body:        `{"access_token":"eyJ","expires_in":300,"nested":[{"Password":"secret","name":"jane"}]}`,
+			want:        "{\n\t\"access_token\": \"REDACTED\",\n\t\"expires_in\": 300,\n\t\"nested\": [\n\t\t{\n\t\t\t\"Password\": \"REDACTED\",\n\t\t\t\"name\": \"jane\"\n\t\t}\n\t]\n}",
+		},
+		{
+			name:        "form",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "grant_type=password&username=jane&password=secret&client_secret=secret",
+			want:        "client_secret=REDACTED&grant_type=password&password=REDACTED&username=jane",
+		},
+		{name: "text", contentType: "text/plain", body: "bad gateway", want: "bad gateway"},
+		{
+			name:        "larger than the limit",
+			contentType: "application/json",
+			body:        `{"password":"` + strings.Repeat("x", maxLoggedBodySize) + `"}`,
+			want:        "(body larger than 65536 bytes not logged)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatBody(tt.contentType, []byte(tt.body)); got != tt.want {
+				t.Errorf("formatBody() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "https://api.example.com/dnsDomains/1", want: "https://api.example.com/dnsDomains/1"},
+		{url: "https://api.example.com/dnsDomains?name=example.com", want: "https://api.example.com/dnsDomains?name=example.com"},
+		{url: "https://auth.example.com/token?code=1&Access_Token=eyJ", want: "https://auth.example.com/token?Access_Token=REDACTED&code=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			u, err := url.Parse(tt.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := redactURL(u); got != tt.want {
+				t.Errorf("redactURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer eyJ")
+	header.Set("Set-Cookie", "session=1")
+	header.Add("Accept", "application/json")
+	header.Add("Accept", "text/plain")
+	header.Set("X-Transaction-Id", "terraform-1")
+
+	want := map[string]string{
+		"Authorization":    redacted,
+		"Set-Cookie":       redacted,
+		"Accept":           "application/json, text/plain",
+		"X-Transaction-Id": "terraform-1",
+	}
+	if got := formatHeaders(header); !reflect.DeepEqual(got, want) {
+		t.Errorf("formatHeaders() = %v, want %v", got, want)
+	}
+}
+
+func TestLogSubsystem(t *testing.T) {
+	ctx := context.Background()
+	if got := logSubsystem(ctx); got != SubsystemAPI {
+		t.Errorf("logSubsystem() = %q without subsystem, want %q", got, SubsystemAPI)
+	}
+	if got := logSubsystem(WithLogSubsystem(ctx, SubsystemDNS)); got != SubsystemDNS {
+		t.Errorf("logSubsystem() = %q, want %q", got, SubsystemDNS)
+	}
+}
+
+func TestDebugEnabled(t *testing.T) {
+	tests := []struct {
+		name       string
+		subsystem  string
+		plusserver string
+		provider   string
+		root       string
+		want       bool
+	}{
+		{name: "not set"},
+		{name: "TF_LOG debug", root: "DEBUG", want: true},
+		{name: "TF_LOG trace lower case", root: "trace", want: true},
+		{name: "TF_LOG info", root: "INFO"},
+		{name: "TF_LOG unknown level", root: "1", want: true},
+		{name: "TF_LOG_PROVIDER overrides TF_LOG", provider: "WARN", root: "DEBUG"},
+		{name: "subsystem overrides TF_LOG_PROVIDER", subsystem: "DEBUG", provider: "ERROR", want: true},
+		{name: "subsystem off", subsystem: "OFF", root: "TRACE"},
+		{name: "provider debug", plusserver: "debug", want: true},
+		{name: "provider overrides TF_LOG", plusserver: "info", root: "DEBUG"},
+		{name: "subsystem overrides provider", subsystem: "debug", plusserver: "off", want: true},
+		{name: "unknown provider level is inherited", plusserver: "verbose", root: "DEBUG", want: true},
+		{name: "unknown subsystem level is inherited", subsystem: "verbose", plusserver: "warn", root: "DEBUG"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TF_LOG_PROVIDER_PLUSSERVER_DNS", tt.subsystem)
+			t.Setenv("TF_LOG_PROVIDER_PLUSSERVER", tt.plusserver)
+			t.Setenv("TF_LOG_PROVIDER", tt.provider)
+			t.Setenv("TF_LOG", tt.root)
+			if got := debugEnabled(SubsystemDNS); got != tt.want {
+				t.Errorf("debugEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestLoggingTransportBody(t *testing.T) {
+	large := strings.Repeat("x", 2*maxLoggedBodySize)
+
+	tests := []struct {
+		name  string
+		level string
+		body  string
+	}{
+		{name: "debug off", level: "INFO", body: large},
+		{name: "debug on", level: "DEBUG", body: `{"password":"secret"}`},
+		{name: "debug on larger than the limit", level: "DEBUG", body: large},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TF_LOG_PROVIDER_PLUSSERVER_API", "")
+			t.Setenv("TF_LOG_PROVIDER_PLUSSERVER", "")
+			t.Setenv("TF_LOG_PROVIDER", "")
+			t.Setenv("TF_LOG", tt.level)
+
+			respBody := io.NopCloser(strings.NewReader(tt.body))
+			transport := NewLoggingTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				sent, err := io.ReadAll(req.Body)
+				if err != nil {
+					return nil, err
+				}
+				if string(sent) != tt.body {
+					t.Errorf("request body of %d bytes sent, want %d bytes", len(sent), len(tt.body))
+				}
+				return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: respBody}, nil
+			}))
+
+			req, err := http.NewRequest(http.MethodPost, "https://api.example.com/dnsDomains", bytes.NewBufferString(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := transport.RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.level != "DEBUG" && resp.Body != respBody {
+				t.Error("the response body was replaced although debug logging is off")
+			}
+			received, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(received) != tt.body {
+				t.Errorf("response body of %d bytes received, want %d bytes", len(received), len(tt.body))
+			}
+		})
+	}
+}
@@ -2,9 +2,12 @@ package api
 
 import (
 	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"golang.org/x/oauth2"
 	"net/http"
-	"time"
+	"sort"
+	"strings"
 )
 
 type OAuthConfig struct {
@@ -13,28 +16,116 @@ type OAuthConfig struct {
 	Username     string
 	Password     string
 	TokenURL     string
+	// IssuerURL is used to discover the token url. TokenURL is used if the discovery fails.
+	IssuerURL string
+	// AccessToken is used as is instead of running the password grant.
+	AccessToken string
+	// TokenCommand is run in the system shell to obtain an access token instead of running the password grant.
+	TokenCommand string
+	// CacheToken stores the token of the password grant in the user cache directory and reuses it while the
+	// access token or the refresh token is valid.
+	CacheToken bool
 }
 
-func NewClient(config *OAuthConfig) (*http.Client, error) {
-	ctx := context.Background()
+func (c *OAuthConfig) validate() error {
+	if c.AccessToken != "" || c.TokenCommand != "" {
+		return nil
+	}
+	var missing []string
+	for name, value := range map[string]string{
+		"client_id":               c.ClientID,
+		"username":                c.Username,
+		"password":                c.Password,
+		"token_url or issuer_url": c.TokenURL + c.IssuerURL,
+	} {
+		if value == "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("either an access token, a token command or the password grant credentials have to be "+
+			"configured, missing %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
 
-	conf := &oauth2.Config{
-		ClientID:     config.ClientID,
-		ClientSecret: config.ClientSecret,
-		Scopes:       []string{},
-		Endpoint: oauth2.Endpoint{
-			TokenURL:  config.TokenURL,
-			AuthStyle: oauth2.AuthStyleInParams,
-		},
+// tokenURL discovers the token url from the issuer url. TokenURL is used if no issuer url is configured
+// or the discovery fails.
+func (c *OAuthConfig) tokenURL(ctx context.Context, client *http.Client) (string, error) {
+	if c.IssuerURL == "" {
+		return c.TokenURL, nil
+	}
+	tokenURL, err := DiscoverTokenURL(ctx, client, c.IssuerURL)
+	if err == nil {
+		return tokenURL, nil
 	}
+	if c.TokenURL == "" {
+		return "", err
+	}
+	tflog.SubsystemWarn(ctx, SubsystemAuth, "unable to discover the token url, falling back to the configured token url",
+		map[string]interface{}{"error": err.Error()})
+	return c.TokenURL, nil
+}
 
-	httpClient := &http.Client{Timeout: 10 * time.Second}
-	ctx = context.WithValue(ctx, oauth2.HTTPClient, httpClient)
+func NewClient(ctx context.Context, config *OAuthConfig, httpConfig *HTTPConfig) (*http.Client, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	// the token source keeps the context for refreshing the token after ctx has been cancelled
+	ctx = context.WithoutCancel(ctx)
+	ctx = tflog.SetField(ctx, "client_id", config.ClientID)
 
-	tok, err := conf.PasswordCredentialsToken(ctx, config.Username, config.Password)
+	httpClient, err := httpConfig.client()
 	if err != nil {
 		return nil, err
 	}
-	client := conf.Client(ctx, tok)
-	return client, nil
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, httpClient)
+
+	source := &reauthTokenSource{ctx: WithLogSubsystem(ctx, SubsystemAuth)}
+	switch {
+	case config.AccessToken != "":
+		source.grant = staticTokenGrant(config.AccessToken)
+	case config.TokenCommand != "":
+		source.grant = commandTokenGrant(config.TokenCommand)
+	default:
+		tokenURL, err := config.tokenURL(WithLogSubsystem(ctx, SubsystemAuth), httpClient)
+		if err != nil {
+			return nil, err
+		}
+		ctx = tflog.SetField(ctx, "token_url", tokenURL)
+		source.ctx = WithLogSubsystem(ctx, SubsystemAuth)
+
+		conf := &oauth2.Config{
+			ClientID:     config.ClientID,
+			ClientSecret: config.ClientSecret,
+			Scopes:       []string{},
+			Endpoint: oauth2.Endpoint{
+				TokenURL:  tokenURL,
+				AuthStyle: oauth2.AuthStyleInParams,
+			},
+		}
+		source.conf = conf
+		if config.CacheToken {
+			if source.cache, err = newTokenCache(tokenURL, config.ClientID, config.Username); err != nil {
+				return nil, err
+			}
+		}
+		source.grant = func(ctx context.Context) (*oauth2.Token, error) {
+			tflog.SubsystemDebug(ctx, SubsystemAuth, "requesting token with password grant", map[string]interface{}{
+				"username": config.Username,
+			})
+			return conf.PasswordCredentialsToken(ctx, config.Username, config.Password)
+		}
+	}
+	// fetch the first token right away so invalid credentials are reported while configuring the provider
+	if _, err = source.Token(); err != nil {
+		return nil, err
+	}
+
+	return &http.Client{
+		Timeout:   httpConfig.timeout(),
+		Transport: &authTransport{source: source, base: httpClient.Transport},
+	}, nil
 }
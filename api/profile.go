@@ -0,0 +1,76 @@
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Profile holds the values of a named profile of the shared config file, keyed like the provider arguments,
+// e.g. client_id, client_secret, username, password, token_url, issuer_url, env and api_url.
+type Profile map[string]string
+
+// DefaultConfigFile returns the path of the shared config file, ~/.plusserver/config.
+func DefaultConfigFile() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".plusserver", "config"), nil
+}
+
+// LoadProfile reads the profile name from the INI formatted config file at path:
+//
+//	[test]
+//	client_id = terraform
+//	username  = jane
+//	env       = test
+//
+// Sections may also be written as [profile test].
+func LoadProfile(path string, name string) (Profile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read config file: %w", err)
+	}
+	defer file.Close()
+
+	var profile Profile
+	var section string
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line[1:len(line)-1]), "profile "))
+			if section == name && profile == nil {
+				profile = Profile{}
+			}
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			return nil, fmt.Errorf("%s:%d: expected key = value", path, lineNumber)
+		}
+		if section == name {
+			profile[strings.TrimSpace(key)] = unquote(strings.TrimSpace(value))
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read config file: %w", err)
+	}
+	if profile == nil {
+		return nil, fmt.Errorf("profile %q not found in %s", name, path)
+	}
+	return profile, nil
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
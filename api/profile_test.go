@@ -0,0 +1,101 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadProfile(t *testing.T) {
+	const config = `# shared config
+[test]
+client_id     = terraform
+client_secret = "secret = with equals"
+username      = jane
+env           = test
+
+; prod uses a token command
+[profile prod]
+token_command = get-plusserver-token --prod
+env           = 'prod'
+
+[test]
+password = password
+`
+
+	tests := []struct {
+		name    string
+		config  string
+		profile string
+		want    Profile
+		wantErr string
+	}{
+		{
+			name:    "profile",
+			config:  config,
+			profile: "test",
+			want: Profile{
+				"client_id":     "terraform",
+				"client_secret": "secret = with equals",
+				"username":      "jane",
+				"env":           "test",
+				"password":      "password",
+			},
+		},
+		{
+			name:    "profile prefix",
+			config:  config,
+			profile: "prod",
+			want:    Profile{"token_command": "get-plusserver-token --prod", "env": "prod"},
+		},
+		{
+			name:    "empty profile",
+			config:  "[empty]\n",
+			profile: "empty",
+			want:    Profile{},
+		},
+		{
+			name:    "missing profile",
+			config:  config,
+			profile: "dev",
+			wantErr: `profile "dev" not found`,
+		},
+		{
+			name:    "invalid line",
+			config:  "[test]\nclient_id terraform\n",
+			profile: "test",
+			wantErr: ":2: expected key = value",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config")
+			if err := os.WriteFile(path, []byte(tt.config), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := LoadProfile(path, tt.profile)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadProfile() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadProfile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadProfileMissingFile(t *testing.T) {
+	if _, err := LoadProfile(filepath.Join(t.TempDir(), "config"), "test"); err == nil {
+		t.Error("LoadProfile() of a missing file returned no error")
+	}
+}
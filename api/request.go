@@ -0,0 +1,57 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+)
+
+// MaxResponseSize limits the size of a response body decoded by Do.
+const MaxResponseSize = 10 << 20
+
+// Empty is used as request or response type of Do for requests without body.
+type Empty struct{}
+
+// Do encodes req as JSON, sends it with method to endpoint and decodes the JSON response into a new Resp.
+// No body is sent if req is nil. An empty response body results in the zero value of Resp.
+// The response body is always closed.
+func Do[Req any, Resp any](ctx context.Context, c *Client, method string, endpoint string, req *Req) (*Resp, error) {
+	body := &bytes.Buffer{}
+	if req != nil {
+		if err := json.NewEncoder(body).Encode(req); err != nil {
+			return nil, fmt.Errorf("unable to encode request: %w", err)
+		}
+	}
+
+	resp, err := c.Send(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxResponseSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read response of %s %s: %w", method, endpoint, err)
+	}
+	if len(data) > MaxResponseSize {
+		return nil, fmt.Errorf("response of %s %s exceeds %d bytes", method, endpoint, MaxResponseSize)
+	}
+
+	var result Resp
+	if len(bytes.TrimSpace(data)) == 0 {
+		return &result, nil
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || mediaType != "application/json" {
+			return nil, fmt.Errorf("unexpected content type %q in response of %s %s", contentType, method, endpoint)
+		}
+	}
+	if err = json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("unable to decode response of %s %s: %w", method, endpoint, err)
+	}
+	return &result, nil
+}
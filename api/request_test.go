@@ -0,0 +1,97 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testRequest struct {
+	Name string `json:"name"`
+}
+
+type testResponse struct {
+	Id int `json:"id"`
+}
+
+func TestDo(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		want        testResponse
+		wantErr     string
+	}{
+		{name: "JSON", status: http.StatusOK, contentType: "application/json; charset=utf-8", body: `{"id":1}`, want: testResponse{Id: 1}},
+		{name: "no content type", status: http.StatusOK, body: `{"id":1}`, want: testResponse{Id: 1}},
+		{name: "empty", status: http.StatusOK, contentType: "application/json"},
+		{name: "HTML", status: http.StatusOK, contentType: "text/html", body: `<html></html>`, wantErr: `unexpected content type "text/html"`},
+		{name: "invalid JSON", status: http.StatusOK, contentType: "application/json", body: `{"id":`, wantErr: "unable to decode response"},
+		{
+			name:        "larger than the limit",
+			status:      http.StatusOK,
+			contentType: "application/json",
+			body:        `{"id":1,"padding":"` + strings.Repeat("x", MaxResponseSize) + `"}`,
+			wantErr:     "exceeds 10485760 bytes",
+		},
+		{name: "status code", status: http.StatusBadRequest, contentType: "application/json", body: `{"error":"invalid"}`, wantErr: "400"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				if r.URL.Path != "/dns/dnsRecords" || r.Method != http.MethodPost || string(body) != "{\"name\":\"www\"}\n" {
+					t.Errorf("got %s %s %q, want the encoded request", r.Method, r.URL.Path, body)
+				}
+				if tt.contentType != "" {
+					w.Header().Set("Content-Type", tt.contentType)
+				} else {
+					// keep the server from detecting the content type
+					w.Header()["Content-Type"] = nil
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+			client := &Client{baseURL: server.URL, apiService: "dns", httpClient: server.Client()}
+
+			got, err := Do[testRequest, testResponse](context.Background(), client, http.MethodPost, "dnsRecords",
+				&testRequest{Name: "www"})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Do() error = %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *got != tt.want {
+				t.Errorf("Do() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDoRequestError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+	client := &Client{baseURL: server.URL, apiService: "dns", httpClient: server.Client(), transactionPrefix: "ci"}
+
+	_, err := Do[Empty, testResponse](context.Background(), client, http.MethodGet, "dnsDomains/1", nil)
+	var requestErr *RequestError
+	if !errors.As(err, &requestErr) {
+		t.Fatalf("Do() error = %v, want a RequestError", err)
+	}
+	if requestErr.StatusCode != http.StatusNotFound || !strings.HasPrefix(requestErr.TransactionId, "ci-") {
+		t.Errorf("RequestError = %+v, want status code 404 and a transaction id with the prefix", requestErr)
+	}
+}
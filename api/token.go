@@ -0,0 +1,218 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"golang.org/x/oauth2"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenSource is implemented by all token sources used by the authTransport.
+type tokenSource interface {
+	Token() (*oauth2.Token, error)
+	// Invalidate is called when the api rejected token.
+	Invalidate(token *oauth2.Token)
+}
+
+// reauthTokenSource hands out the current token and refreshes it with its refresh token when it expires.
+// If the refresh fails, e.g. because the keycloak session expired or was revoked, the configured grant
+// is run again.
+type reauthTokenSource struct {
+	mu    sync.Mutex
+	ctx   context.Context
+	conf  *oauth2.Config
+	grant func(ctx context.Context) (*oauth2.Token, error)
+	token *oauth2.Token
+	// cache is optional and keeps the token across terraform runs
+	cache *tokenCache
+}
+
+func (s *reauthTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == nil && s.cache != nil {
+		if s.token = s.cache.load(); s.token != nil {
+			tflog.SubsystemDebug(s.ctx, SubsystemAuth, "loaded token from cache", map[string]interface{}{
+				"expiry": s.token.Expiry,
+			})
+		}
+	}
+	if s.token.Valid() {
+		return s.token, nil
+	}
+
+	if s.conf != nil && s.token != nil && s.token.RefreshToken != "" {
+		tflog.SubsystemDebug(s.ctx, SubsystemAuth, "refreshing token")
+		token, err := s.conf.TokenSource(s.ctx, s.token).Token()
+		if err == nil {
+			s.setToken(token)
+			return token, nil
+		}
+		tflog.SubsystemWarn(s.ctx, SubsystemAuth, "unable to refresh token, authenticating again", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	token, err := s.grant(s.ctx)
+	if err != nil {
+		return nil, err
+	}
+	tflog.SubsystemDebug(s.ctx, SubsystemAuth, "received token", map[string]interface{}{
+		"expiry": token.Expiry,
+	})
+	s.setToken(token)
+	return token, nil
+}
+
+func (s *reauthTokenSource) setToken(token *oauth2.Token) {
+	s.token = token
+	if s.cache == nil {
+		return
+	}
+	if err := s.cache.save(token); err != nil {
+		tflog.SubsystemWarn(s.ctx, SubsystemAuth, "unable to cache token", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+// Invalidate marks token as expired so the next call to Token refreshes it. Nothing happens if the token
+// has already been replaced in the meantime.
+func (s *reauthTokenSource) Invalidate(token *oauth2.Token) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == nil || token == nil || s.token.AccessToken != token.AccessToken {
+		return
+	}
+	expired := *s.token
+	expired.Expiry = time.Now().Add(-time.Minute)
+	s.token = &expired
+}
+
+// staticTokenGrant returns a grant handing out a fixed access token. The token expires with the exp claim if
+// it is a JWT.
+func staticTokenGrant(accessToken string) func(ctx context.Context) (*oauth2.Token, error) {
+	return func(ctx context.Context) (*oauth2.Token, error) {
+		token := &oauth2.Token{AccessToken: accessToken, TokenType: "Bearer", Expiry: jwtExpiry(accessToken)}
+		if !token.Expiry.IsZero() && token.Expiry.Before(time.Now()) {
+			return nil, fmt.Errorf("the access token expired at %s", token.Expiry.Format(time.RFC3339))
+		}
+		return token, nil
+	}
+}
+
+// tokenCommandTimeout limits how long the token command may run.
+const tokenCommandTimeout = time.Minute
+
+// commandTokenGrant returns a grant running command in the system shell. The access token is read from the
+// standard output of the command and cached until the exp claim of the JWT.
+func commandTokenGrant(command string) func(ctx context.Context) (*oauth2.Token, error) {
+	return func(ctx context.Context) (*oauth2.Token, error) {
+		tflog.SubsystemDebug(ctx, SubsystemAuth, "requesting token with token command")
+		ctx, cancel := context.WithTimeout(ctx, tokenCommandTimeout)
+		defer cancel()
+
+		var cmd *exec.Cmd
+		if runtime.GOOS == "windows" {
+			cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+		} else {
+			cmd = exec.CommandContext(ctx, "sh", "-c", command)
+		}
+		var stderr strings.Builder
+		cmd.Stderr = &stderr
+		output, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("token command failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		accessToken := strings.TrimSpace(string(output))
+		if accessToken == "" {
+			return nil, errors.New("token command returned an empty token")
+		}
+		return &oauth2.Token{AccessToken: accessToken, TokenType: "Bearer", Expiry: jwtExpiry(accessToken)}, nil
+	}
+}
+
+// jwtExpiry returns the exp claim of a JWT without verifying it. The zero time is returned if token is no JWT
+// or has no exp claim.
+func jwtExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err = json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(claims.Exp, 0)
+}
+
+// authTransport adds the token of source to every request. If the api rejects the token with
+// 401 Unauthorized the token is invalidated and the request is retried once with a new token.
+type authTransport struct {
+	source tokenSource
+	base   http.RoundTripper
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.source.Token()
+	if err != nil {
+		closeRequestBody(req)
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(withToken(req, token, req.Body))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	if req.Body != nil && req.GetBody == nil {
+		// the body has been consumed and can't be sent again
+		return resp, nil
+	}
+
+	tflog.SubsystemDebug(req.Context(), SubsystemAuth, "token rejected by the api, retrying with a new token")
+	t.source.Invalidate(token)
+	retryToken, err := t.source.Token()
+	if err != nil {
+		return resp, nil
+	}
+	var body io.ReadCloser
+	if req.GetBody != nil {
+		if body, err = req.GetBody(); err != nil {
+			return resp, nil
+		}
+	}
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	_ = resp.Body.Close()
+	return t.base.RoundTrip(withToken(req, retryToken, body))
+}
+
+func withToken(req *http.Request, token *oauth2.Token, body io.ReadCloser) *http.Request {
+	authReq := req.Clone(req.Context())
+	authReq.Body = body
+	token.SetAuthHeader(authReq)
+	return authReq
+}
+
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+}
@@ -0,0 +1,256 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// tokenServer is a keycloak token endpoint counting the grants it answered.
+type tokenServer struct {
+	*httptest.Server
+	// expiresIn is the lifetime of issued tokens in seconds
+	expiresIn int
+	// refreshFails makes the refresh token grant fail with invalid_grant
+	refreshFails bool
+
+	mu       sync.Mutex
+	password int
+	refresh  int
+}
+
+func newTokenServer(t *testing.T, expiresIn int, refreshFails bool) *tokenServer {
+	s := &tokenServer{expiresIn: expiresIn, refreshFails: refreshFails}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveToken))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *tokenServer) serveToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var accessToken string
+	switch r.PostForm.Get("grant_type") {
+	case "password":
+		s.password++
+		accessToken = fmt.Sprintf("password-%d", s.password)
+	case "refresh_token":
+		s.refresh++
+		if s.refreshFails {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant","error_description":"Session not active"}`))
+			return
+		}
+		accessToken = fmt.Sprintf("refresh-%d", s.refresh)
+	default:
+		http.Error(w, "unsupported grant type", http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token":  accessToken,
+		"token_type":    "Bearer",
+		"expires_in":    s.expiresIn,
+		"refresh_token": "refresh",
+	})
+}
+
+func (s *tokenServer) grants() (password int, refresh int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.password, s.refresh
+}
+
+func (s *tokenServer) config() *OAuthConfig {
+	return &OAuthConfig{ClientID: "terraform", ClientSecret: "secret", Username: "jane", Password: "secret",
+		TokenURL: s.URL}
+}
+
+func TestAuthTransportRetriesRejectedToken(t *testing.T) {
+	tokens := newTokenServer(t, 300, false)
+
+	var mu sync.Mutex
+	var authorizations, bodies []string
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+		if r.Header.Get("Authorization") == "Bearer password-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer apiServer.Close()
+
+	client, err := NewClient(context.Background(), tokens.config(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := http.NewRequest(http.MethodPost, apiServer.URL+"/dnsRecords", strings.NewReader(`{"name":"www"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status code = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	wantAuthorizations := []string{"Bearer password-1", "Bearer refresh-1"}
+	if strings.Join(authorizations, ",") != strings.Join(wantAuthorizations, ",") {
+		t.Errorf("authorizations = %v, want %v", authorizations, wantAuthorizations)
+	}
+	for i, body := range bodies {
+		if body != `{"name":"www"}` {
+			t.Errorf("body of request %d = %q, want the body of the original request", i+1, body)
+		}
+	}
+}
+
+func TestAuthTransportAuthenticatesAgainWhenRefreshFails(t *testing.T) {
+	// tokens expiring within the expiry delta of oauth2 are refreshed before every request
+	tokens := newTokenServer(t, 1, true)
+
+	var authorization string
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer apiServer.Close()
+
+	client, err := NewClient(context.Background(), tokens.config(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get(apiServer.URL + "/dnsDomains")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	if authorization != "Bearer password-2" {
+		t.Errorf("authorization = %q, want the token of a new password grant", authorization)
+	}
+	if password, refresh := tokens.grants(); password != 2 || refresh != 1 {
+		t.Errorf("%d password grants and %d refresh grants, want 2 and 1", password, refresh)
+	}
+}
+
+func TestJwtExpiry(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		want  time.Time
+	}{
+		{name: "exp claim", token: jwt(`{"sub":"jane","exp":1700000000}`), want: time.Unix(1700000000, 0)},
+		{name: "padded payload", token: "eyJhbGciOiJSUzI1NiJ9." + base64.URLEncoding.EncodeToString([]byte(`{"exp":1700000000}`)) + ".c2ln", want: time.Unix(1700000000, 0)},
+		{name: "no exp claim", token: jwt(`{"sub":"jane"}`)},
+		{name: "payload no JSON", token: jwt(`exp`)},
+		{name: "payload no base64", token: "a.!!!.c"},
+		{name: "opaque token", token: "2YotnFZFEjr1zCsicMWpAA"},
+		{name: "too many parts", token: jwt(`{"exp":1700000000}`) + ".d"},
+		{name: "empty", token: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jwtExpiry(tt.token); !got.Equal(tt.want) {
+				t.Errorf("jwtExpiry() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStaticTokenGrant(t *testing.T) {
+	expired := jwt(fmt.Sprintf(`{"exp":%d}`, time.Now().Add(-time.Hour).Unix()))
+	if _, err := staticTokenGrant(expired)(context.Background()); err == nil {
+		t.Error("staticTokenGrant() accepted an expired JWT")
+	}
+
+	token, err := staticTokenGrant("2YotnFZFEjr1zCsicMWpAA")(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "2YotnFZFEjr1zCsicMWpAA" || !token.Expiry.IsZero() {
+		t.Errorf("staticTokenGrant() = %+v, want the opaque token without expiry", token)
+	}
+}
+
+func TestCommandTokenGrant(t *testing.T) {
+	token, err := commandTokenGrant("echo 2YotnFZFEjr1zCsicMWpAA")(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "2YotnFZFEjr1zCsicMWpAA" {
+		t.Errorf("access token = %q, want the trimmed output of the command", token.AccessToken)
+	}
+
+	if _, err = commandTokenGrant("exit 1")(context.Background()); err == nil {
+		t.Error("commandTokenGrant() accepted a failing command")
+	}
+	if _, err = commandTokenGrant("echo")(context.Background()); err == nil {
+		t.Error("commandTokenGrant() accepted an empty token")
+	}
+}
+
+func jwt(payload string) string {
+	return "eyJhbGciOiJSUzI1NiJ9." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".c2lnbmF0dXJl"
+}
+
+func TestOAuthConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  OAuthConfig
+		wantErr string
+	}{
+		{name: "access token", config: OAuthConfig{AccessToken: "eyJ"}},
+		{name: "token command", config: OAuthConfig{TokenCommand: "echo eyJ"}},
+		{
+			name:   "password grant with issuer url",
+			config: OAuthConfig{ClientID: "terraform", Username: "jane", Password: "secret", IssuerURL: "https://auth.example.com"},
+		},
+		{
+			name:   "password grant with token url",
+			config: OAuthConfig{ClientID: "terraform", Username: "jane", Password: "secret", TokenURL: "https://auth.example.com/token"},
+		},
+		{
+			// there is no default issuer per env
+			name:    "password grant without issuer url and token url",
+			config:  OAuthConfig{ClientID: "terraform", Username: "jane", Password: "secret"},
+			wantErr: "missing token_url or issuer_url",
+		},
+		{name: "nothing", wantErr: "missing client_id, password, token_url or issuer_url, username"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.validate()
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("validate() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DefaultTimeout is used for every HTTP request when no timeout is configured.
+const DefaultTimeout = 30 * time.Second
+
+// HTTPConfig holds the HTTP settings of the keycloak token client and the api client.
+type HTTPConfig struct {
+	// Timeout limits a single HTTP request including reading the response body.
+	Timeout time.Duration
+	// CACertFile and CACertPEM add certificate authorities to the system pool.
+	CACertFile string
+	CACertPEM  string
+	// ClientCertPEM and ClientKeyPEM enable mutual TLS when both are set.
+	ClientCertPEM string
+	ClientKeyPEM  string
+	// InsecureSkipVerify disables the server certificate verification. Only meant for test environments.
+	InsecureSkipVerify bool
+	// ProxyURL overrides the proxy taken from HTTP_PROXY, HTTPS_PROXY and NO_PROXY.
+	ProxyURL string
+	// TransactionCaller is sent as X-Transaction-Caller with every api request. Defaults to DefaultTransactionCaller.
+	TransactionCaller string
+	// TransactionPrefix is prepended to the generated X-Transaction-Id of every api request.
+	TransactionPrefix string
+}
+
+func (c *HTTPConfig) timeout() time.Duration {
+	if c == nil || c.Timeout <= 0 {
+		return DefaultTimeout
+	}
+	return c.Timeout
+}
+
+func (c *HTTPConfig) client() (*http.Client, error) {
+	transport, err := c.transport()
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Timeout: c.timeout(), Transport: NewLoggingTransport(transport)}, nil
+}
+
+func (c *HTTPConfig) transport() (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if c == nil {
+		return transport, nil
+	}
+
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	if c.ProxyURL != "" {
+		proxyURL, err := url.Parse(c.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy url: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	return transport, nil
+}
+
+func (c *HTTPConfig) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+
+	if c.CACertFile != "" || c.CACertPEM != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if c.CACertFile != "" {
+			pem, err := ioutil.ReadFile(c.CACertFile)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read ca certificate file: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificate found in ca certificate file %s", c.CACertFile)
+			}
+		}
+		if c.CACertPEM != "" && !pool.AppendCertsFromPEM([]byte(c.CACertPEM)) {
+			return nil, errors.New("no certificate found in ca certificate pem")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if c.ClientCertPEM != "" || c.ClientKeyPEM != "" {
+		cert, err := tls.X509KeyPair([]byte(c.ClientCertPEM), []byte(c.ClientKeyPEM))
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 )
 
+// PlusServerTime is a timestamp of the api. null and empty strings are decoded as zero time.
 type PlusServerTime time.Time
 
 func (m *PlusServerTime) UnmarshalJSON(data []byte) error {
@@ -12,4 +13,24 @@ func (m *PlusServerTime) UnmarshalJSON(data []byte) error {
 		return nil
 	}
 	return json.Unmarshal(data, (*time.Time)(m))
-}
\ No newline at end of file
+}
+
+// MarshalJSON encodes the time in RFC 3339 format and the zero time as null.
+func (m PlusServerTime) MarshalJSON() ([]byte, error) {
+	if m.Time().IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(m.String())
+}
+
+func (m PlusServerTime) Time() time.Time {
+	return time.Time(m)
+}
+
+// String returns the time in RFC 3339 format or an empty string for the zero time.
+func (m PlusServerTime) String() string {
+	if m.Time().IsZero() {
+		return ""
+	}
+	return m.Time().Format(time.RFC3339)
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestPlusServerTimeJSON(t *testing.T) {
+	tests := []struct {
+		name       string
+		json       string
+		want       time.Time
+		wantString string
+		wantJSON   string
+	}{
+		{
+			name:       "RFC 3339",
+			json:       `"2021-10-05T12:30:00+02:00"`,
+			want:       time.Date(2021, 10, 5, 10, 30, 0, 0, time.UTC),
+			wantString: "2021-10-05T12:30:00+02:00",
+			wantJSON:   `"2021-10-05T12:30:00+02:00"`,
+		},
+		{name: "null", json: `null`, wantJSON: `null`},
+		{name: "empty", json: `""`, wantJSON: `null`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got PlusServerTime
+			if err := json.Unmarshal([]byte(tt.json), &got); err != nil {
+				t.Fatal(err)
+			}
+			if !got.Time().Equal(tt.want) {
+				t.Errorf("decoded %v, want %v", got.Time(), tt.want)
+			}
+			if got.String() != tt.wantString {
+				t.Errorf("String() = %q, want %q", got.String(), tt.wantString)
+			}
+			encoded, err := json.Marshal(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(encoded) != tt.wantJSON {
+				t.Errorf("encoded %s, want %s", encoded, tt.wantJSON)
+			}
+		})
+	}
+}
@@ -1,15 +1,32 @@
 package main
 
 import (
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5/tf5server"
+	"github.com/hashicorp/terraform-plugin-mux/tf5muxserver"
 	"github.com/plusserver/terraform-provider-plusserver/plusserver"
+	"log"
 )
 
 func main() {
-	plugin.Serve(&plugin.ServeOpts{
-		ProviderFunc: func() *schema.Provider {
-			return plusserver.Provider()
-		},
+	ctx := context.Background()
+
+	// the SDKv2 provider is listed first, it configures the api client shared with the framework provider
+	primary := plusserver.Provider()
+	muxServer, err := tf5muxserver.NewMuxServer(ctx,
+		primary.GRPCProvider,
+		providerserver.NewProtocol5(plusserver.NewFrameworkProvider(primary)),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = tf5server.Serve("registry.terraform.io/plusserver/plusserver", func() tfprotov5.ProviderServer {
+		return muxServer.ProviderServer()
 	})
+	if err != nil {
+		log.Fatal(err)
+	}
 }
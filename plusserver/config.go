@@ -0,0 +1,84 @@
+package plusserver
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/plusserver/terraform-provider-plusserver/api"
+	"os"
+)
+
+// deprecatedEnvVar is an environment variable read before the PLUSSERVER_ prefix was introduced.
+type deprecatedEnvVar struct {
+	name        string
+	replacement string
+}
+
+// deprecatedEnvVars are still used as fallback for the provider arguments but cause a warning.
+var deprecatedEnvVars = map[string]deprecatedEnvVar{
+	"client_id":     {name: "CLIENT_ID", replacement: "PLUSSERVER_CLIENT_ID"},
+	"client_secret": {name: "CLIENT_SECRET", replacement: "PLUSSERVER_CLIENT_SECRET"},
+	"username":      {name: "USERNAME", replacement: "PLUSSERVER_USERNAME"},
+	"password":      {name: "PASSWORD", replacement: "PLUSSERVER_PASSWORD"},
+	"token_url":     {name: "TOKEN_URL", replacement: "PLUSSERVER_TOKEN_URL"},
+	"env":           {name: "API_ENV", replacement: "PLUSSERVER_ENV"},
+}
+
+// providerConfig resolves the provider arguments from the configuration, the selected profile and the
+// environment variables.
+type providerConfig struct {
+	d       *schema.ResourceData
+	profile api.Profile
+	diags   diag.Diagnostics
+}
+
+func newProviderConfig(d *schema.ResourceData) (*providerConfig, error) {
+	profile, err := loadProfile(d)
+	if err != nil {
+		return nil, err
+	}
+	return &providerConfig{d: d, profile: profile}, nil
+}
+
+// loadProfile reads the profile selected by the profile argument. No profile is returned if none is selected.
+func loadProfile(d *schema.ResourceData) (api.Profile, error) {
+	name := d.Get("profile").(string)
+	if name == "" {
+		return nil, nil
+	}
+	path := d.Get("config_file").(string)
+	if path == "" {
+		var err error
+		if path, err = api.DefaultConfigFile(); err != nil {
+			return nil, err
+		}
+	}
+	return api.LoadProfile(path, name)
+}
+
+// getString returns the value of the argument key if it is set in the provider configuration. Otherwise the
+// value of the profile is preferred over the PLUSSERVER_ environment variables and the deprecated environment
+// variables without prefix.
+func (c *providerConfig) getString(key string) string {
+	if config := c.d.GetRawConfig(); !config.IsNull() && !config.GetAttr(key).IsNull() {
+		return c.d.Get(key).(string)
+	}
+	if value, ok := c.profile[key]; ok {
+		return value
+	}
+	if value := c.d.Get(key).(string); value != "" {
+		return value
+	}
+	if envVar, ok := deprecatedEnvVars[key]; ok {
+		if value := os.Getenv(envVar.name); value != "" {
+			c.diags = append(c.diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("Deprecated environment variable %s", envVar.name),
+				Detail: fmt.Sprintf("The environment variable %s is deprecated and will be removed, use %s instead.",
+					envVar.name, envVar.replacement),
+			})
+			return value
+		}
+	}
+	return ""
+}
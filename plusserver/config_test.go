@@ -0,0 +1,128 @@
+package plusserver
+
+import (
+	"context"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/go-cty/cty/convert"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/plusserver/terraform-provider-plusserver/api"
+	"testing"
+)
+
+// providerData returns the provider configuration as passed to the configure function. Unlike
+// schema.TestResourceDataRaw the raw config is set, so arguments set in the configuration can be told apart from
+// defaults.
+func providerData(t *testing.T, config map[string]string) *schema.ResourceData {
+	t.Helper()
+	provider := Provider()
+	providerSchema, err := provider.GetSchema(&terraform.ProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	attributes := map[string]cty.Value{}
+	for name, attributeType := range providerSchema.Provider.ImpliedType().AttributeTypes() {
+		attributes[name] = cty.NullVal(attributeType)
+		if value, ok := config[name]; ok {
+			if attributes[name], err = convert.Convert(cty.StringVal(value), attributeType); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	var data *schema.ResourceData
+	provider.ConfigureContextFunc = func(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+		data = d
+		return nil, nil
+	}
+	value := cty.ObjectVal(attributes)
+	resourceConfig := terraform.NewResourceConfigShimmed(value, providerSchema.Provider)
+	resourceConfig.CtyValue = value
+	diags := provider.Configure(context.Background(), resourceConfig)
+	if diags.HasError() {
+		t.Fatalf("configuring the provider failed: %v", diags)
+	}
+	return data
+}
+
+func TestProviderConfigGetString(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      map[string]string
+		profile     api.Profile
+		env         map[string]string
+		want        string
+		wantWarning bool
+	}{
+		{name: "not set"},
+		{
+			name:    "configuration",
+			config:  map[string]string{"username": "config"},
+			profile: api.Profile{"username": "profile"},
+			env:     map[string]string{"PLUSSERVER_USERNAME": "env", "USERNAME": "deprecated"},
+			want:    "config",
+		},
+		{
+			name:    "profile",
+			profile: api.Profile{"username": "profile"},
+			env:     map[string]string{"PLUSSERVER_USERNAME": "env", "USERNAME": "deprecated"},
+			want:    "profile",
+		},
+		{
+			name: "environment variable",
+			env:  map[string]string{"PLUSSERVER_USERNAME": "env", "USERNAME": "deprecated"},
+			want: "env",
+		},
+		{
+			name:        "deprecated environment variable",
+			env:         map[string]string{"USERNAME": "deprecated"},
+			want:        "deprecated",
+			wantWarning: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PLUSSERVER_USERNAME", "")
+			t.Setenv("USERNAME", "")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			config := &providerConfig{
+				d:       providerData(t, tt.config),
+				profile: tt.profile,
+			}
+
+			if got := config.getString("username"); got != tt.want {
+				t.Errorf("getString() = %q, want %q", got, tt.want)
+			}
+			warned := len(config.diags) == 1 && config.diags[0].Severity == diag.Warning
+			if warned != tt.wantWarning || len(config.diags) > 1 {
+				t.Errorf("diagnostics = %v, want a deprecation warning: %v", config.diags, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestProviderDefaultTtl(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]string
+		env    string
+		want   int
+	}{
+		{name: "not set"},
+		{name: "environment variable", env: "3600", want: 3600},
+		{name: "configuration", config: map[string]string{"default_ttl": "600"}, env: "3600", want: 600},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PLUSSERVER_DEFAULT_TTL", tt.env)
+			if got := providerData(t, tt.config).Get("default_ttl").(int); got != tt.want {
+				t.Errorf("default_ttl = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
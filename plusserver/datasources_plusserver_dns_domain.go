@@ -2,110 +2,138 @@ package plusserver
 
 import (
 	"context"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/plusserver/terraform-provider-plusserver/api"
 	"github.com/plusserver/terraform-provider-plusserver/api/dns"
-	"log"
 	"strconv"
 	"time"
 )
 
-func dataSourceDomain() *schema.Resource {
-	return &schema.Resource{
-		ReadContext: dataSourceDomainRead,
-		Schema: map[string]*schema.Schema{
-			"name": {
-				Type: schema.TypeString,
+var _ datasource.DataSourceWithConfigure = (*domainDataSource)(nil)
+
+var domainDataSourceItemType = types.ObjectType{AttrTypes: map[string]attr.Type{
+	"domain_id":  types.Int64Type,
+	"name":       types.StringType,
+	"created_at": types.StringType,
+}}
+
+type domainDataSource struct {
+	client dns.API
+}
+
+type domainDataSourceModel struct {
+	Id        types.String `tfsdk:"id"`
+	Name      types.String `tfsdk:"name"`
+	DomainId  types.Int64  `tfsdk:"domain_id"`
+	CreatedAt types.String `tfsdk:"created_at"`
+	Domains   types.List   `tfsdk:"domains"`
+}
+
+type domainDataSourceItemModel struct {
+	DomainId  types.Int64  `tfsdk:"domain_id"`
+	Name      types.String `tfsdk:"name"`
+	CreatedAt types.String `tfsdk:"created_at"`
+}
+
+func newDomainDataSource() datasource.DataSource {
+	return &domainDataSource{}
+}
+
+func (d *domainDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_domain"
+}
+
+func (d *domainDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The ID of this resource.",
+				Computed:    true,
+			},
+			"name": schema.StringAttribute{
 				Required: true,
 			},
-			"domain_id": {
-				Type: schema.TypeInt,
+			"domain_id": schema.Int64Attribute{
 				Computed: true,
 				Optional: true,
 			},
-			"domains": {
-				Type: schema.TypeList,
-				Computed: true,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"domain_id": {
-							Type: schema.TypeInt,
-							Computed: true,
-						},
-						"name": {
-							Type: schema.TypeString,
-							Computed: true,
-						},
-					},
-				},
+			"created_at": schema.StringAttribute{
+				Description: "Creation time of the domain in RFC 3339 format",
+				Computed:    true,
+			},
+			"domains": schema.ListAttribute{
+				ElementType: domainDataSourceItemType,
+				Computed:    true,
 			},
 		},
 	}
 }
 
+func (d *domainDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	meta, err := metaFromProviderData(req.ProviderData)
+	if err != nil {
+		resp.Diagnostics.AddError("Unexpected provider data", err.Error())
+		return
+	}
+	if meta != nil {
+		d.client = meta.dns
+	}
+}
 
-func dataSourceDomainRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*dns.Client)
-
-	var diags diag.Diagnostics
+func (d *domainDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx = api.WithLogSubsystem(ctx, api.SubsystemDNS)
 
-	domains, err := client.SearchDomains(ctx, d.Get("name").(string))
+	var config domainDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	log.Printf("[INFO] Received: %+v", domains)
+	domains, err := d.client.SearchDomains(ctx, config.Name.ValueString())
 	if err != nil {
-		return diag.FromErr(err)
+		resp.Diagnostics.Append(diagFromErr(err)...)
+		return
 	}
+	tflog.SubsystemDebug(ctx, api.SubsystemDNS, "received domains", map[string]interface{}{
+		"count": len(domains.DnsDomainList),
+	})
 
 	if len(domains.DnsDomainList) > 1 {
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "ambiguous domain selector",
-			Detail:   "the api has returned more than one domain that matched your criteria",
-		})
-		return diags
-	} else if len(domains.DnsDomainList) == 0{
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "no domain found",
-			Detail:   "the api has returned no domain that matched your criteria",
-		})
-		return diags
+		resp.Diagnostics.AddError("ambiguous domain selector",
+			"the api has returned more than one domain that matched your criteria")
+		return
+	} else if len(domains.DnsDomainList) == 0 {
+		resp.Diagnostics.AddError("no domain found", "the api has returned no domain that matched your criteria")
+		return
 	}
 
-	domainItems := flattenDomainsData(&domains.DnsDomainList)
-	log.Printf("[INFO] domainItems: %+v", domainItems)
-	if err = d.Set("domains", domainItems); err != nil {
-		return diag.FromErr(err)
+	domainItems, diags := types.ListValueFrom(ctx, domainDataSourceItemType, flattenDomainsData(domains.DnsDomainList))
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
+	config.Domains = domainItems
 
 	// select first element and set the domain_id as domains[0].domain_id
-	err = d.Set("domain_id", domains.DnsDomainList[0].DnsDomainId)
-	if err != nil {
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "error setting domain_id",
-			Detail:   err.Error(),
-		})
-		return diags
-	}
-	d.SetId(strconv.FormatInt(time.Now().Unix(), 10))
+	config.DomainId = types.Int64Value(int64(domains.DnsDomainList[0].DnsDomainId))
+	config.CreatedAt = types.StringValue(domains.DnsDomainList[0].CreateDateTime.String())
+	config.Id = types.StringValue(strconv.FormatInt(time.Now().Unix(), 10))
 
-	return diags
+	resp.Diagnostics.Append(resp.State.Set(ctx, &config)...)
 }
 
-func flattenDomainsData(items *[]dns.DomainListResponse) []interface{} {
-	if items != nil {
-		domainItems := make([]interface{}, len(*items), len(*items))
-
-		for i, domainItem := range *items {
-			di := make(map[string]interface{})
-			di["domain_id"] = domainItem.DnsDomainId
-			di["name"] = domainItem.Name
-
-			domainItems[i] = di
+func flattenDomainsData(items []dns.Domain) []domainDataSourceItemModel {
+	domainItems := make([]domainDataSourceItemModel, len(items))
+	for i, domainItem := range items {
+		domainItems[i] = domainDataSourceItemModel{
+			DomainId:  types.Int64Value(int64(domainItem.DnsDomainId)),
+			Name:      types.StringValue(domainItem.Name),
+			CreatedAt: types.StringValue(domainItem.CreateDateTime.String()),
 		}
-		return domainItems
 	}
-
-	return make([]interface{}, 0)
-}
\ No newline at end of file
+	return domainItems
+}
@@ -0,0 +1,36 @@
+package plusserver
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/plusserver/terraform-provider-plusserver/api"
+	"net/http"
+)
+
+// diagFromErr turns err into an error diagnostic. The endpoint, status code and transaction id of a failed api
+// call are added to the diagnostic, so the call can be looked up in the gateway logs.
+func diagFromErr(err error) diag.Diagnostics {
+	var diags diag.Diagnostics
+	var requestErr *api.RequestError
+	if !errors.As(err, &requestErr) {
+		diags.AddError(err.Error(), "")
+		return diags
+	}
+
+	detail := fmt.Sprintf("Endpoint: %s %s\nTransaction ID: %s\nMessage ID: %s", requestErr.Method, requestErr.URL,
+		requestErr.TransactionId, requestErr.MessageId)
+	if requestErr.Err != nil {
+		diags.AddError(fmt.Sprintf("Request to the plusserver api failed: %s", requestErr.Err), detail)
+		return diags
+	}
+	diags.AddError(fmt.Sprintf("The plusserver api answered with status code %d", requestErr.StatusCode),
+		fmt.Sprintf("%s\nStatus code: %d\nResponse: %s", detail, requestErr.StatusCode, requestErr.Body))
+	return diags
+}
+
+// isNotFound reports whether err is the answer of the api to a request for an object which doesn't exist.
+func isNotFound(err error) bool {
+	var requestErr *api.RequestError
+	return errors.As(err, &requestErr) && requestErr.Err == nil && requestErr.StatusCode == http.StatusNotFound
+}
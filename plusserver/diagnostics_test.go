@@ -0,0 +1,70 @@
+package plusserver
+
+import (
+	"errors"
+	"fmt"
+	"github.com/plusserver/terraform-provider-plusserver/api"
+	"testing"
+)
+
+func TestDiagFromErr(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantSummary string
+		wantDetail  string
+	}{
+		{name: "other error", err: errors.New("name is invalid"), wantSummary: "name is invalid"},
+		{
+			name: "status code",
+			err: fmt.Errorf("reading domain: %w", &api.RequestError{Method: "GET", URL: "https://api.example.com/dnsDomains/1",
+				StatusCode: 500, Body: "internal error", MessageId: "m-1", TransactionId: "t-1"}),
+			wantSummary: "The plusserver api answered with status code 500",
+			wantDetail: "Endpoint: GET https://api.example.com/dnsDomains/1\nTransaction ID: t-1\nMessage ID: m-1\n" +
+				"Status code: 500\nResponse: internal error",
+		},
+		{
+			name: "transport error",
+			err: &api.RequestError{Method: "POST", URL: "https://api.example.com/dnsRecords", MessageId: "m-2",
+				TransactionId: "t-2", Err: errors.New("connection refused")},
+			wantSummary: "Request to the plusserver api failed: connection refused",
+			wantDetail:  "Endpoint: POST https://api.example.com/dnsRecords\nTransaction ID: t-2\nMessage ID: m-2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := diagFromErr(tt.err)
+			if len(diags) != 1 {
+				t.Fatalf("diagFromErr() returned %d diagnostics, want 1", len(diags))
+			}
+			if diags[0].Summary() != tt.wantSummary {
+				t.Errorf("summary = %q, want %q", diags[0].Summary(), tt.wantSummary)
+			}
+			if diags[0].Detail() != tt.wantDetail {
+				t.Errorf("detail = %q, want %q", diags[0].Detail(), tt.wantDetail)
+			}
+		})
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "404", err: fmt.Errorf("reading domain: %w", &api.RequestError{StatusCode: 404}), want: true},
+		{name: "500", err: &api.RequestError{StatusCode: 500}},
+		{name: "transport error", err: &api.RequestError{Err: errors.New("connection refused")}},
+		{name: "other error", err: errors.New("not found")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isNotFound(tt.err); got != tt.want {
+				t.Errorf("isNotFound() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
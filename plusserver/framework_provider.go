@@ -0,0 +1,103 @@
+package plusserver
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	fwschema "github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"sort"
+)
+
+// frameworkProvider serves the resources and data sources implemented with terraform-plugin-framework. It is
+// muxed with the SDKv2 provider, which owns the provider configuration until the migration is complete.
+type frameworkProvider struct {
+	primary *schema.Provider
+}
+
+// NewFrameworkProvider returns the terraform-plugin-framework provider. primary has to be configured before the
+// returned provider by the mux server, its meta is shared with the framework resources.
+func NewFrameworkProvider(primary *schema.Provider) provider.Provider {
+	return &frameworkProvider{primary: primary}
+}
+
+func (p *frameworkProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
+	resp.TypeName = "plusserver"
+}
+
+// Schema converts the provider schema of the SDKv2 provider, since muxed providers need identical schemas.
+func (p *frameworkProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp *provider.SchemaResponse) {
+	names := make([]string, 0, len(p.primary.Schema))
+	for name := range p.primary.Schema {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	attributes := make(map[string]fwschema.Attribute, len(names))
+	for _, name := range names {
+		s := p.primary.Schema[name]
+		switch s.Type {
+		case schema.TypeString:
+			attributes[name] = fwschema.StringAttribute{Optional: s.Optional, Required: s.Required,
+				Sensitive: s.Sensitive, Description: s.Description, DeprecationMessage: s.Deprecated}
+		case schema.TypeInt:
+			attributes[name] = fwschema.Int64Attribute{Optional: s.Optional, Required: s.Required,
+				Sensitive: s.Sensitive, Description: s.Description, DeprecationMessage: s.Deprecated}
+		case schema.TypeBool:
+			attributes[name] = fwschema.BoolAttribute{Optional: s.Optional, Required: s.Required,
+				Sensitive: s.Sensitive, Description: s.Description, DeprecationMessage: s.Deprecated}
+		default:
+			resp.Diagnostics.AddError("Unsupported provider argument",
+				fmt.Sprintf("the type of the provider argument %s can't be converted", name))
+		}
+	}
+	resp.Schema = fwschema.Schema{Attributes: attributes}
+}
+
+func (p *frameworkProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	meta, ok := p.primary.Meta().(*providerMeta)
+	if !ok {
+		resp.Diagnostics.AddError("Provider not configured",
+			"the SDKv2 provider has to be configured before the framework provider")
+		return
+	}
+	resp.DataSourceData = meta
+	resp.ResourceData = meta
+}
+
+func (p *frameworkProvider) Resources(ctx context.Context) []func() resource.Resource {
+	return []func() resource.Resource{
+		newDomainResource,
+		newDomainRecordResource,
+	}
+}
+
+func (p *frameworkProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
+	return []func() datasource.DataSource{
+		newDomainDataSource,
+	}
+}
+
+// metaFromProviderData returns the meta passed to the Configure method of resources and data sources.
+// Nil is returned while the provider is not configured yet.
+func metaFromProviderData(providerData interface{}) (*providerMeta, error) {
+	if providerData == nil {
+		return nil, nil
+	}
+	meta, ok := providerData.(*providerMeta)
+	if !ok {
+		return nil, fmt.Errorf("unexpected provider data %T", providerData)
+	}
+	return meta, nil
+}
+
+// stringValueOrNull returns a null value for empty strings, the api returns empty strings for unset attributes.
+func stringValueOrNull(s string) types.String {
+	if s == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(s)
+}
@@ -0,0 +1,42 @@
+package plusserver
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"testing"
+)
+
+func TestFrameworkProviderSchema(t *testing.T) {
+	primary := Provider()
+	resp := &provider.SchemaResponse{}
+	NewFrameworkProvider(primary).Schema(context.Background(), provider.SchemaRequest{}, resp)
+	assertNoDiags(t, resp.Diagnostics)
+
+	for name, s := range primary.Schema {
+		attribute, ok := resp.Schema.Attributes[name]
+		if !ok {
+			t.Errorf("provider argument %s is missing", name)
+			continue
+		}
+		if attribute.IsOptional() != s.Optional || attribute.IsRequired() != s.Required ||
+			attribute.IsSensitive() != s.Sensitive || attribute.GetDescription() != s.Description {
+			t.Errorf("provider argument %s differs from the SDKv2 provider", name)
+		}
+	}
+	if len(resp.Schema.Attributes) != len(primary.Schema) {
+		t.Errorf("%d provider arguments, want %d", len(resp.Schema.Attributes), len(primary.Schema))
+	}
+}
+
+func TestMetaFromProviderData(t *testing.T) {
+	meta := &providerMeta{}
+	if got, err := metaFromProviderData(meta); err != nil || got != meta {
+		t.Errorf("metaFromProviderData() = %v, %v, want the provider meta", got, err)
+	}
+	if got, err := metaFromProviderData(nil); err != nil || got != nil {
+		t.Errorf("metaFromProviderData(nil) = %v, %v, want nil while the provider is not configured", got, err)
+	}
+	if _, err := metaFromProviderData("meta"); err == nil {
+		t.Error("metaFromProviderData() accepted data of another type")
+	}
+}
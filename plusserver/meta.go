@@ -0,0 +1,19 @@
+package plusserver
+
+import (
+	"github.com/plusserver/terraform-provider-plusserver/api/dns"
+)
+
+// providerMeta is returned by providerConfigure and passed to all resources and data sources.
+type providerMeta struct {
+	dns dns.API
+	// defaults are applied to resources which leave the corresponding argument unset
+	defaults providerDefaults
+}
+
+// providerDefaults holds the default_ arguments of the provider. Empty values are not applied.
+type providerDefaults struct {
+	companyId  string
+	contractId string
+	ttl        int
+}
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/plusserver/terraform-provider-plusserver/api"
 	"github.com/plusserver/terraform-provider-plusserver/api/dns"
+	"time"
 )
 
 func Provider() *schema.Provider {
@@ -14,63 +16,186 @@ func Provider() *schema.Provider {
 		Schema: map[string]*schema.Schema{
 			"client_id": {
 				Type: schema.TypeString,
-				Required: true,
-				DefaultFunc: schema.EnvDefaultFunc("CLIENT_ID", ""),
+				Optional: true,
+				DefaultFunc: schema.EnvDefaultFunc("PLUSSERVER_CLIENT_ID", ""),
 				Description: "the client id of the keycloak app",
 			},
 			"client_secret": {
 				Type: schema.TypeString,
-				Required: true,
+				Optional: true,
 				Sensitive: true,
-				DefaultFunc: schema.EnvDefaultFunc("CLIENT_SECRET", ""),
+				DefaultFunc: schema.EnvDefaultFunc("PLUSSERVER_CLIENT_SECRET", ""),
 				Description: "the client secret of the keycloak app",
 			},
 			"username": {
 				Type: schema.TypeString,
-				Required: true,
-				DefaultFunc: schema.EnvDefaultFunc("USERNAME", ""),
+				Optional: true,
+				DefaultFunc: schema.EnvDefaultFunc("PLUSSERVER_USERNAME", ""),
 				Description: "the username to authenticate against keycloak",
 			},
 			"password": {
 				Type: schema.TypeString,
-				Required: true,
+				Optional: true,
 				Sensitive: true,
-				DefaultFunc: schema.EnvDefaultFunc("PASSWORD", ""),
+				DefaultFunc: schema.EnvDefaultFunc("PLUSSERVER_PASSWORD", ""),
 				Description: "the password to authenticate against keycloak",
 			},
 			"token_url": {
 				Type: schema.TypeString,
-				Required: true,
-				DefaultFunc: schema.EnvDefaultFunc("TOKEN_URL", ""),
-				Description: "the keycloak token url",
+				Optional: true,
+				DefaultFunc: schema.EnvDefaultFunc("PLUSSERVER_TOKEN_URL", ""),
+				Description: "the keycloak token url. Only used if issuer_url is not set or the discovery fails",
+			},
+			"issuer_url": {
+				Type: schema.TypeString,
+				Optional: true,
+				DefaultFunc: schema.EnvDefaultFunc("PLUSSERVER_ISSUER_URL", ""),
+				Description: "the keycloak issuer url used to discover the token url. " +
+					"There is no default per env, either issuer_url or token_url is required for the password grant",
+			},
+			"access_token": {
+				Type: schema.TypeString,
+				Optional: true,
+				Sensitive: true,
+				DefaultFunc: schema.EnvDefaultFunc("PLUSSERVER_ACCESS_TOKEN", ""),
+				ConflictsWith: []string{"token_command"},
+				Description: "access token used instead of the password grant",
+			},
+			"token_command": {
+				Type: schema.TypeString,
+				Optional: true,
+				DefaultFunc: schema.EnvDefaultFunc("PLUSSERVER_TOKEN_COMMAND", ""),
+				ConflictsWith: []string{"access_token"},
+				Description: "command printing an access token, used instead of the password grant. " +
+					"The token is cached until it expires and the command is run again afterwards",
+			},
+			"token_cache": {
+				Type: schema.TypeBool,
+				Optional: true,
+				DefaultFunc: schema.EnvDefaultFunc("PLUSSERVER_TOKEN_CACHE", false),
+				Description: "cache the token of the password grant in the user cache directory and reuse it " +
+					"in later terraform runs while it is valid. The default value is false",
 			},
 			"env": {
 				Type: schema.TypeString,
 				Optional: true,
-				DefaultFunc: schema.EnvDefaultFunc("API_ENV", "test"),
-				Description: "api environment",
+				DefaultFunc: schema.EnvDefaultFunc("PLUSSERVER_ENV", ""),
+				Description: "api environment. The default value is test",
+			},
+			"api_url": {
+				Type: schema.TypeString,
+				Optional: true,
+				DefaultFunc: schema.EnvDefaultFunc("PLUSSERVER_API_URL", ""),
+				Description: "the api gateway url. Defaults to the gateway of the api environment",
+			},
+			"profile": {
+				Type: schema.TypeString,
+				Optional: true,
+				DefaultFunc: schema.EnvDefaultFunc("PLUSSERVER_PROFILE", ""),
+				Description: "name of the profile in the shared config file to take credentials, env and api_url from. " +
+					"Arguments set in the provider configuration override the values of the profile",
+			},
+			"config_file": {
+				Type: schema.TypeString,
+				Optional: true,
+				DefaultFunc: schema.EnvDefaultFunc("PLUSSERVER_CONFIG_FILE", ""),
+				Description: "path of the shared config file. The default value is ~/.plusserver/config",
+			},
+			"request_timeout": {
+				Type: schema.TypeInt,
+				Optional: true,
+				Default: 30,
+				ValidateFunc: validation.IntAtLeast(1),
+				Description: "timeout in seconds for a single request against keycloak or the api. The default value is 30 seconds",
+			},
+			"default_company_id": {
+				Type: schema.TypeString,
+				Optional: true,
+				DefaultFunc: schema.EnvDefaultFunc("PLUSSERVER_DEFAULT_COMPANY_ID", ""),
+				Description: "company id of plusserver_domain resources which don't set company_id",
+			},
+			"default_contract_id": {
+				Type: schema.TypeString,
+				Optional: true,
+				DefaultFunc: schema.EnvDefaultFunc("PLUSSERVER_DEFAULT_CONTRACT_ID", ""),
+				Description: "contract id of plusserver_domain resources which don't set contract_id",
+			},
+			"default_ttl": {
+				Type: schema.TypeInt,
+				Optional: true,
+				DefaultFunc: schema.EnvDefaultFunc("PLUSSERVER_DEFAULT_TTL", nil),
+				ValidateFunc: validation.IntAtLeast(1),
+				Description: "ttl in seconds of plusserver_domain_record resources which don't set ttl. The default value is 300 seconds",
+			},
+			"record_batch_window": {
+				Type: schema.TypeInt,
+				Optional: true,
+				Default: 0,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description: "time in milliseconds record creations of a domain are collected to create them with a single api request. " +
+					"The default value 0 creates every record with its own request",
+			},
+			"ca_cert_file": {
+				Type: schema.TypeString,
+				Optional: true,
+				Description: "path to a PEM encoded CA bundle trusted in addition to the system certificates",
+			},
+			"ca_cert_pem": {
+				Type: schema.TypeString,
+				Optional: true,
+				Description: "PEM encoded CA bundle trusted in addition to the system certificates",
+			},
+			"client_cert": {
+				Type: schema.TypeString,
+				Optional: true,
+				RequiredWith: []string{"client_key"},
+				Description: "PEM encoded client certificate for mutual TLS",
+			},
+			"client_key": {
+				Type: schema.TypeString,
+				Optional: true,
+				Sensitive: true,
+				RequiredWith: []string{"client_cert"},
+				Description: "PEM encoded private key of the client certificate",
+			},
+			"insecure_skip_verify": {
+				Type: schema.TypeBool,
+				Optional: true,
+				Default: false,
+				Description: "skip the TLS certificate verification. Only use this for test environments",
+			},
+			"proxy_url": {
+				Type: schema.TypeString,
+				Optional: true,
+				Description: "proxy used for keycloak and api requests. Defaults to the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables",
+			},
+			"transaction_caller": {
+				Type: schema.TypeString,
+				Optional: true,
+				DefaultFunc: schema.EnvDefaultFunc("PLUSSERVER_TRANSACTION_CALLER", api.DefaultTransactionCaller),
+				Description: "caller name sent as X-Transaction-Caller with every api request. The default value is terraform-provider-plusserver",
+			},
+			"transaction_prefix": {
+				Type: schema.TypeString,
+				Optional: true,
+				DefaultFunc: schema.EnvDefaultFunc("PLUSSERVER_TRANSACTION_PREFIX", ""),
+				Description: "prefix of the X-Transaction-Id sent with every api request, e.g. the id of a pipeline run",
 			},
 		},
-		DataSourcesMap: map[string]*schema.Resource{
-			"plusserver_domain": dataSourceDomain(),
-		},
-		ResourcesMap: map[string]*schema.Resource{
-			"plusserver_domain":        resourceDomain(),
-			"plusserver_domain_record": resourceDomainRecord(),
-		},
+		// resources and data sources are served by the framework provider, see NewFrameworkProvider
+		DataSourcesMap:       map[string]*schema.Resource{},
+		ResourcesMap:         map[string]*schema.Resource{},
 		ConfigureContextFunc: providerConfigure,
 	}
 
 	return p
 }
 
-func buildAPI(d *schema.ResourceData) string {
-	var env string
-	if d.Get("env").(string) == "prod" {
+func buildAPI(env string) string {
+	if env == "prod" {
 		// prod has no prefix
 		return fmt.Sprintf("https://tool.ps-intern.de/api-gateway-legacy/gateway/entity")
 	} else {
-		env = d.Get("env").(string)
 		return fmt.Sprintf("https://tool-%s.ps-intern.de/api-gateway-legacy/gateway/entity", env)
 	}
 }
@@ -79,13 +204,52 @@ func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}
 	// Warning or errors can be collected in a slice type
 	var diags diag.Diagnostics
 
-	dnsClient, err := dns.NewDNSClient(&api.OAuthConfig{
-		ClientID:     d.Get("client_id").(string),
-		ClientSecret: d.Get("client_secret").(string),
-		Username:     d.Get("username").(string),
-		Password:     d.Get("password").(string),
-		TokenURL:     d.Get("token_url").(string),
-	}, buildAPI(d))
+	config, err := newProviderConfig(d)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to load profile",
+			Detail:   err.Error(),
+		})
+
+		return nil, diags
+	}
+
+	credentials := &api.OAuthConfig{
+		AccessToken:  config.getString("access_token"),
+		TokenCommand: config.getString("token_command"),
+	}
+	env := config.getString("env")
+	if env == "" {
+		env = "test"
+	}
+	apiURL := config.getString("api_url")
+	if apiURL == "" {
+		apiURL = buildAPI(env)
+	}
+	// the password grant arguments are only read if needed, so stale environment variables don't cause warnings
+	if credentials.AccessToken == "" && credentials.TokenCommand == "" {
+		credentials.ClientID = config.getString("client_id")
+		credentials.ClientSecret = config.getString("client_secret")
+		credentials.Username = config.getString("username")
+		credentials.Password = config.getString("password")
+		credentials.TokenURL = config.getString("token_url")
+		credentials.IssuerURL = config.getString("issuer_url")
+		credentials.CacheToken = d.Get("token_cache").(bool)
+	}
+	diags = append(diags, config.diags...)
+
+	dnsClient, err := dns.NewDNSClient(ctx, credentials, &api.HTTPConfig{
+		Timeout:            time.Duration(d.Get("request_timeout").(int)) * time.Second,
+		CACertFile:         d.Get("ca_cert_file").(string),
+		CACertPEM:          d.Get("ca_cert_pem").(string),
+		ClientCertPEM:      d.Get("client_cert").(string),
+		ClientKeyPEM:       d.Get("client_key").(string),
+		InsecureSkipVerify: d.Get("insecure_skip_verify").(bool),
+		ProxyURL:           d.Get("proxy_url").(string),
+		TransactionCaller:  d.Get("transaction_caller").(string),
+		TransactionPrefix:  d.Get("transaction_prefix").(string),
+	}, apiURL)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -96,5 +260,19 @@ func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}
 		return nil, diags
 	}
 
-	return dnsClient, diags
+	var dnsAPI dns.API = dnsClient
+	if window := d.Get("record_batch_window").(int); window > 0 {
+		dnsAPI = dns.NewBatchClient(dnsClient, time.Duration(window)*time.Millisecond)
+	}
+	// the records of a domain share one domain lookup and record listing instead of sending them per record
+	dnsAPI = dns.NewCacheClient(dnsAPI)
+
+	return &providerMeta{
+		dns: dnsAPI,
+		defaults: providerDefaults{
+			companyId:  d.Get("default_company_id").(string),
+			contractId: d.Get("default_contract_id").(string),
+			ttl:        d.Get("default_ttl").(int),
+		},
+	}, diags
 }
@@ -0,0 +1,70 @@
+package plusserver
+
+import (
+	"fmt"
+	"github.com/plusserver/terraform-provider-plusserver/api/dns"
+	"strings"
+)
+
+// checkRecordConflicts checks that record can be added to a domain with the records. The records with the
+// recordIds are replaced or adopted by record and left out.
+//
+//   - CNAME records are not allowed at the apex of a domain
+//   - a CNAME record can't coexist with other records of the same name
+func checkRecordConflicts(records []*dns.Record, record *dns.Record, recordIds ...string) error {
+	if record.Type == "CNAME" && record.Name == apexRecordName {
+		return fmt.Errorf("CNAME records are not allowed at the apex of a domain")
+	}
+
+	for _, existing := range records {
+		if containsString(recordIds, existing.DnsResourceRecordId) || !strings.EqualFold(existing.Name, record.Name) {
+			continue
+		}
+		if record.Type == "CNAME" && existing.Type == "CNAME" {
+			return fmt.Errorf("the CNAME record %s exists already as record %s. Set allow_overwrite to overwrite it",
+				record.Name, existing.DnsResourceRecordId)
+		}
+		if record.Type == "CNAME" {
+			return fmt.Errorf("the CNAME record %s can't coexist with the %s record %s of the same name",
+				record.Name, existing.Type, existing.DnsResourceRecordId)
+		}
+		if existing.Type == "CNAME" {
+			return fmt.Errorf("the %s record %s can't coexist with the CNAME record %s of the same name",
+				record.Type, record.Name, existing.DnsResourceRecordId)
+		}
+	}
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// findAdoptedRecord returns the record taken over by record with allow_overwrite: the record with the same name,
+// type and content or else the only record with the same name and type. Nil is returned if there is none.
+func findAdoptedRecord(records []*dns.Record, record *dns.Record) (*dns.Record, error) {
+	existing, err := findRecord(records, "", recordFingerprint(record))
+	if err != nil || existing != nil {
+		return existing, err
+	}
+
+	var matches []*dns.Record
+	for _, existing := range records {
+		if strings.EqualFold(existing.Name, record.Name) && existing.Type == record.Type {
+			matches = append(matches, existing)
+		}
+	}
+	if len(matches) > 1 {
+		return nil, fmt.Errorf("the domain has %d %s records named %s, allow_overwrite can't decide which one to "+
+			"overwrite", len(matches), record.Type, record.Name)
+	}
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,103 @@
+package plusserver
+
+import (
+	"github.com/plusserver/terraform-provider-plusserver/api/dns"
+	"strings"
+	"testing"
+)
+
+func TestCheckRecordConflicts(t *testing.T) {
+	records := []*dns.Record{
+		{DnsResourceRecordId: "1", DnsDomainId: 1, Name: "www", Type: "A", Content: "10.0.0.1"},
+		{DnsResourceRecordId: "2", DnsDomainId: 1, Name: "Mail", Type: "MX", Content: "10 mx.example.com"},
+		{DnsResourceRecordId: "3", DnsDomainId: 1, Name: "blog", Type: "CNAME", Content: "www.example.com"},
+	}
+
+	tests := []struct {
+		name      string
+		record    *dns.Record
+		recordIds []string
+		wantErr   string
+	}{
+		{name: "new name", record: &dns.Record{Name: "api", Type: "CNAME", Content: "www.example.com"}},
+		{name: "other type of the same name", record: &dns.Record{Name: "www", Type: "AAAA", Content: "::1"}},
+		{name: "second record of the same type", record: &dns.Record{Name: "www", Type: "A", Content: "10.0.0.2"}},
+		{
+			name:    "CNAME at the apex",
+			record:  &dns.Record{Name: "@", Type: "CNAME", Content: "www.example.com"},
+			wantErr: "not allowed at the apex",
+		},
+		{
+			name:    "CNAME next to another record",
+			record:  &dns.Record{Name: "www", Type: "CNAME", Content: "web.example.com"},
+			wantErr: "can't coexist with the A record 1",
+		},
+		{
+			name:    "CNAME next to another record in other case",
+			record:  &dns.Record{Name: "mail", Type: "CNAME", Content: "web.example.com"},
+			wantErr: "can't coexist with the MX record 2",
+		},
+		{
+			name:    "record next to a CNAME",
+			record:  &dns.Record{Name: "blog", Type: "TXT", Content: "v=spf1 -all"},
+			wantErr: "can't coexist with the CNAME record 3",
+		},
+		{
+			name:    "second CNAME",
+			record:  &dns.Record{Name: "blog", Type: "CNAME", Content: "web.example.com"},
+			wantErr: "Set allow_overwrite",
+		},
+		{
+			name:      "replaced record is left out",
+			record:    &dns.Record{Name: "blog", Type: "CNAME", Content: "web.example.com"},
+			recordIds: []string{"", "3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkRecordConflicts(records, tt.record, tt.recordIds...)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("checkRecordConflicts() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("checkRecordConflicts() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFindAdoptedRecord(t *testing.T) {
+	records := []*dns.Record{
+		{DnsResourceRecordId: "1", DnsDomainId: 1, Name: "www", Type: "A", Content: "10.0.0.1"},
+		{DnsResourceRecordId: "2", DnsDomainId: 1, Name: "WWW", Type: "AAAA", Content: "::1"},
+		{DnsResourceRecordId: "3", DnsDomainId: 1, Name: "mail", Type: "A", Content: "10.0.0.3"},
+		{DnsResourceRecordId: "4", DnsDomainId: 1, Name: "mail", Type: "A", Content: "10.0.0.4"},
+	}
+
+	tests := []struct {
+		name    string
+		record  *dns.Record
+		want    string
+		wantErr bool
+	}{
+		{name: "same content", record: &dns.Record{DnsDomainId: 1, Name: "www", Type: "A", Content: "10.0.0.1"}, want: "1"},
+		{name: "other content", record: &dns.Record{DnsDomainId: 1, Name: "www", Type: "A", Content: "10.0.0.2"}, want: "1"},
+		{name: "other case", record: &dns.Record{DnsDomainId: 1, Name: "www", Type: "AAAA", Content: "::2"}, want: "2"},
+		{name: "none", record: &dns.Record{DnsDomainId: 1, Name: "www", Type: "TXT", Content: "hello"}},
+		{name: "same content of several", record: &dns.Record{DnsDomainId: 1, Name: "mail", Type: "A", Content: "10.0.0.4"}, want: "4"},
+		{name: "several", record: &dns.Record{DnsDomainId: 1, Name: "mail", Type: "A", Content: "10.0.0.5"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := findAdoptedRecord(records, tt.record)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("findAdoptedRecord() error = %v, want error %v", err, tt.wantErr)
+			}
+			if id := recordId(got); id != tt.want {
+				t.Errorf("findAdoptedRecord() = %q, want %q", id, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package plusserver
+
+import (
+	"fmt"
+	"strings"
+)
+
+// apexRecordName is the api name of records at the apex of a domain. Record names are relative to the
+// domain in the api.
+const apexRecordName = "@"
+
+// relativeRecordName returns the api name of a record. name may be relative to the domain, "@" for the apex or
+// fully qualified with or without a trailing dot. DNS names are case insensitive, the api name is lower case.
+func relativeRecordName(name string, domain string) (string, error) {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	name = strings.ToLower(name)
+	if name == apexRecordName {
+		return apexRecordName, nil
+	}
+
+	qualified := strings.HasSuffix(name, ".")
+	name = strings.TrimSuffix(name, ".")
+	if name == domain {
+		return apexRecordName, nil
+	}
+	if strings.HasSuffix(name, "."+domain) && name != "."+domain {
+		return strings.TrimSuffix(name, "."+domain), nil
+	}
+	if qualified {
+		return "", fmt.Errorf("the name %s. is not part of the domain %s", name, domain)
+	}
+	return name, nil
+}
+
+// validateRecordName checks the labels of the api name of a record. A wildcard is only allowed as the whole
+// leftmost label, like *.example.com or *.dev.example.com.
+func validateRecordName(relativeName string) error {
+	if relativeName == apexRecordName {
+		return nil
+	}
+	for i, label := range strings.Split(relativeName, ".") {
+		if label == "" {
+			return fmt.Errorf("the name %s contains an empty label", relativeName)
+		}
+		if strings.Contains(label, "*") && (i > 0 || label != "*") {
+			return fmt.Errorf("the name %s contains a wildcard which is not the whole leftmost label", relativeName)
+		}
+	}
+	return nil
+}
+
+// recordFqdn returns the fully qualified name of a record without trailing dot.
+func recordFqdn(relativeName string, domain string) string {
+	domain = strings.TrimSuffix(domain, ".")
+	if relativeName == apexRecordName {
+		return domain
+	}
+	return relativeName + "." + domain
+}
@@ -0,0 +1,94 @@
+package plusserver
+
+import (
+	"testing"
+)
+
+func TestRelativeRecordName(t *testing.T) {
+	tests := []struct {
+		name    string
+		domain  string
+		want    string
+		wantErr bool
+	}{
+		{name: "www", domain: "example.com", want: "www"},
+		{name: "WWW", domain: "example.com", want: "www"},
+		{name: "www.dev", domain: "example.com", want: "www.dev"},
+		{name: "@", domain: "example.com", want: "@"},
+		{name: "example.com", domain: "example.com", want: "@"},
+		{name: "Example.COM.", domain: "example.com", want: "@"},
+		{name: "www.example.com", domain: "example.com", want: "www"},
+		{name: "www.example.com.", domain: "example.com.", want: "www"},
+		{name: "WWW.Example.com", domain: "example.COM", want: "www"},
+		{name: "*", domain: "example.com", want: "*"},
+		{name: "*.example.com", domain: "example.com", want: "*"},
+		{name: "*.dev.example.com", domain: "example.com", want: "*.dev"},
+		// a relative name may contain the domain name as long as it doesn't end with it
+		{name: "example.com.dev", domain: "example.com", want: "example.com.dev"},
+		{name: "myexample.com", domain: "example.com", want: "myexample.com"},
+		{name: "www.other.com.", domain: "example.com", wantErr: true},
+		{name: "myexample.com.", domain: "example.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := relativeRecordName(tt.name, tt.domain)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("relativeRecordName() error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("relativeRecordName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateRecordName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{name: "@"},
+		{name: "www"},
+		{name: "www.dev"},
+		{name: "*"},
+		{name: "*.dev"},
+		{name: "_acme-challenge"},
+		{name: "", wantErr: true},
+		{name: "www..dev", wantErr: true},
+		{name: ".www", wantErr: true},
+		{name: "www.", wantErr: true},
+		{name: "www.*", wantErr: true},
+		{name: "w*", wantErr: true},
+		{name: "**", wantErr: true},
+		{name: "dev.*.www", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateRecordName(tt.name); (err != nil) != tt.wantErr {
+				t.Errorf("validateRecordName() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRecordFqdn(t *testing.T) {
+	tests := []struct {
+		name   string
+		domain string
+		want   string
+	}{
+		{name: "@", domain: "example.com", want: "example.com"},
+		{name: "www", domain: "example.com.", want: "www.example.com"},
+		{name: "*.dev", domain: "example.com", want: "*.dev.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := recordFqdn(tt.name, tt.domain); got != tt.want {
+				t.Errorf("recordFqdn() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
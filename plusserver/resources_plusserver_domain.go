@@ -2,227 +2,360 @@ package plusserver
 
 import (
 	"context"
-	"github.com/hashicorp/go-multierror"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/plusserver/terraform-provider-plusserver/api/dns"
 	"strconv"
+	"time"
 )
 
-func resourceDomain() *schema.Resource {
-	return &schema.Resource{
+var (
+	_ resource.ResourceWithConfigure    = (*domainResource)(nil)
+	_ resource.ResourceWithImportState  = (*domainResource)(nil)
+	_ resource.ResourceWithModifyPlan   = (*domainResource)(nil)
+	_ resource.ResourceWithUpgradeState = (*domainResource)(nil)
+)
+
+type domainResource struct {
+	client   dns.API
+	defaults *providerDefaults
+}
+
+type domainResourceModel struct {
+	Id                             types.String   `tfsdk:"id"`
+	UnicodeName                    types.String   `tfsdk:"unicode_name"`
+	DomainId                       types.Int64    `tfsdk:"domain_id"`
+	CompanyId                      types.String   `tfsdk:"company_id"`
+	Protected                      types.Bool     `tfsdk:"protected"`
+	ReplicationType                types.String   `tfsdk:"replication_type"`
+	DnsNameserverPairName          types.String   `tfsdk:"dns_nameserver_pair_name"`
+	ReplicationMasterIpAddressList types.List     `tfsdk:"replication_master_ip_address_list"`
+	ContractId                     types.String   `tfsdk:"contract_id"`
+	CreatedAt                      types.String   `tfsdk:"created_at"`
+	Timeouts                       timeouts.Value `tfsdk:"timeouts"`
+}
+
+func newDomainResource() resource.Resource {
+	return &domainResource{}
+}
+
+func (r *domainResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_domain"
+}
+
+func (r *domainResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
 		Description: "Use the plusserver DNS API to create/modify/delete a domain.",
-		CreateContext: resourceDomainCreate,
-		ReadContext:   resourceDomainRead,
-		UpdateContext: resourceDomainUpdate,
-		DeleteContext: resourceDomainDelete,
-		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
-		},
-		Schema: map[string]*schema.Schema{
-			"unicode_name": {
-				Type:     schema.TypeString,
-				ForceNew: true,
+		Version:     1,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The ID of this resource.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"unicode_name": schema.StringAttribute{
 				Description: "Domain name in unicode",
-				Required: true,
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
-			"domain_id": {
-				Type: schema.TypeInt,
+			"domain_id": schema.Int64Attribute{
 				Description: "Exported ID of the domain. Same as \"id\"",
-				Computed: true,
-				Optional: true,
+				Computed:    true,
+				Optional:    true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
 			},
-			"company_id": {
-				Type:     schema.TypeString,
-				Description: "Company ID to set with the domain as metadata",
-				Optional: true,
+			"company_id": schema.StringAttribute{
+				Description: "Company ID to set with the domain as metadata. Defaults to the default_company_id of the provider",
+				Optional:    true,
+				Computed:    true,
 			},
-			"protected": {
-				Type:     schema.TypeBool,
-				Default:  false,
-				ForceNew: true,
+			"protected": schema.BoolAttribute{
 				Description: "Protects the domain from accidental deletion. " +
 					"However this provider will be unable to delete the domain if set to true. " +
 					"The default Value is false",
 				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(false),
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.RequiresReplace(),
+				},
 			},
-			"replication_type": {
-				Type:     schema.TypeString,
+			"replication_type": schema.StringAttribute{
 				Description: "Domain replication type. Can be either Master, Slave, Native or None",
-				ForceNew: true,
-				ValidateFunc: validation.StringInSlice([]string{"Master", "Slave", "Native", "None"}, false),
-				Required: true,
+				Required:    true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("Master", "Slave", "Native", "None"),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
-			"dns_nameserver_pair_name": {
-				Type: schema.TypeString,
-				ForceNew: true,
-				Default: "ns1.plusserver.com",
+			"dns_nameserver_pair_name": schema.StringAttribute{
 				Description: "Domain pair identifier. The default value is ns1.plusserver.com",
-				Optional: true,
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString("ns1.plusserver.com"),
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
-			"replication_master_ip_address_list": {
-				Type: schema.TypeList,
-				Required: true,
+			"replication_master_ip_address_list": schema.ListAttribute{
 				Description: "List of replication servers",
-				Elem: &schema.Schema{
-					Type: schema.TypeString,
-				},
+				ElementType: types.StringType,
+				Required:    true,
 			},
-			"contract_id": {
-				Type:     schema.TypeString,
-				Description: "Contract ID to set with the domain as metadata",
-				Optional: true,
+			"contract_id": schema.StringAttribute{
+				Description: "Contract ID to set with the domain as metadata. Defaults to the default_contract_id of the provider",
+				Optional:    true,
+				Computed:    true,
+			},
+			"created_at": schema.StringAttribute{
+				Description: "Creation time of the domain in RFC 3339 format",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Update: true,
+				Delete: true,
+			}),
+		},
 	}
 }
 
-func resourceDomainUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*dns.Client)
-
-	domainId := d.Id()
-	companyId := d.Get("company_id").(string)
-	protected := d.Get("protected").(bool)
-	contractId := d.Get("contract_id").(string)
-	replicationMasterIpAddressList := d.Get("replication_master_ip_address_list").([]interface{})
+func (r *domainResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		// version 0 was written by the SDKv2 provider, which stored empty strings for unset arguments
+		0: {
+			PriorSchema: &schema.Schema{
+				Attributes: map[string]schema.Attribute{
+					"id":                                 schema.StringAttribute{Computed: true},
+					"unicode_name":                       schema.StringAttribute{Required: true},
+					"domain_id":                          schema.Int64Attribute{Optional: true, Computed: true},
+					"company_id":                         schema.StringAttribute{Optional: true},
+					"protected":                          schema.BoolAttribute{Optional: true, Computed: true},
+					"replication_type":                   schema.StringAttribute{Required: true},
+					"dns_nameserver_pair_name":           schema.StringAttribute{Optional: true, Computed: true},
+					"replication_master_ip_address_list": schema.ListAttribute{ElementType: types.StringType, Required: true},
+					"contract_id":                        schema.StringAttribute{Optional: true},
+					"created_at":                         schema.StringAttribute{Computed: true},
+				},
+				Blocks: map[string]schema.Block{
+					"timeouts": timeouts.Block(ctx, timeouts.Opts{
+						Create: true,
+						Update: true,
+						Delete: true,
+					}),
+				},
+			},
+			StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+				var state domainResourceModel
+				resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+
+				state.CompanyId = stringValueOrNull(state.CompanyId.ValueString())
+				state.ContractId = stringValueOrNull(state.ContractId.ValueString())
+				resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+			},
+		},
+	}
+}
 
-	var ips []string
-	for _, ipAddress := range replicationMasterIpAddressList {
-		elem := ipAddress.(string)
-		ips = append(ips, elem)
-	}
-
-	resp, err := client.UpdateDomain(ctx, domainId, &dns.UpdateDomain{DnsDomain: struct {
-		ReplicationMasterIpAddressList []string `json:"replicationMasterIpAddressList"`
-		Protected                      bool     `json:"protected"`
-		CompanyId                      string   `json:"companyId"`
-		ContractId                     string   `json:"contractId"`
-	}(struct {
-		ReplicationMasterIpAddressList []string
-		Protected                      bool
-		CompanyId                      string
-		ContractId                     string
-	}{ReplicationMasterIpAddressList: ips, Protected: protected, CompanyId: companyId, ContractId: contractId})})
+func (r *domainResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	meta, err := metaFromProviderData(req.ProviderData)
 	if err != nil {
-		return diag.FromErr(err)
+		resp.Diagnostics.AddError("Unexpected provider data", err.Error())
+		return
+	}
+	if meta != nil {
+		r.client = meta.dns
+		r.defaults = &meta.defaults
 	}
+}
 
-	err = d.Set("domain_id", resp.DnsDomain.DnsDomainId)
-	if err != nil {
-		return diag.FromErr(err)
+// ModifyPlan applies the default company and contract id of the provider to arguments which are not configured.
+// Without a default the arguments are planned as null, so removing them from the configuration unsets them.
+func (r *domainResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// the defaults are unknown until the provider is configured
+	if req.Plan.Raw.IsNull() || r.defaults == nil {
+		return
 	}
 
-	d.SetId(strconv.Itoa(resp.DnsDomain.DnsDomainId))
+	for attribute, value := range map[string]string{
+		"company_id":  r.defaults.companyId,
+		"contract_id": r.defaults.contractId,
+	} {
+		var config types.String
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root(attribute), &config)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if config.IsNull() {
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root(attribute), stringValueOrNull(value))...)
+		}
+	}
+}
 
-	return resourceDomainRead(ctx, d, m)
+func (r *domainResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
 }
 
-func resourceDomainDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*dns.Client)
-	var diags diag.Diagnostics
+func (r *domainResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan domainResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	timeout, diags := plan.Timeouts.Create(ctx, 10*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	domainId := d.Id()
+	var ips []string
+	resp.Diagnostics.Append(plan.ReplicationMasterIpAddressList.ElementsAs(ctx, &ips, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	_, err := client.DeleteDomain(ctx, domainId)
+	domain, err := r.client.CreateDomain(ctx, dns.NewCreateDomain(dns.DomainCreate{
+		UnicodeName:                    plan.UnicodeName.ValueString(),
+		CompanyId:                      plan.CompanyId.ValueString(),
+		DnsNameserverPairName:          plan.DnsNameserverPairName.ValueString(),
+		Protected:                      plan.Protected.ValueBool(),
+		ReplicationType:                plan.ReplicationType.ValueString(),
+		ReplicationMasterIpAddressList: ips,
+		ContractId:                     plan.ContractId.ValueString(),
+	}))
 	if err != nil {
-		return diag.FromErr(err)
+		resp.Diagnostics.Append(diagFromErr(err)...)
+		return
 	}
 
-	// implied but we explicitly set it here
-	d.SetId("")
-
-	return diags
+	plan.Id = types.StringValue(strconv.Itoa(domain.DnsDomain.DnsDomainId))
+	plan.DomainId = types.Int64Value(int64(domain.DnsDomain.DnsDomainId))
+	plan.CreatedAt = types.StringValue(domain.DnsDomain.CreateDateTime.String())
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 }
 
+func (r *domainResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state domainResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-func resourceDomainRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*dns.Client)
-	var diags diag.Diagnostics
-	var result error
-
-	domainId := d.Id()
-
-
-	resp, err := client.GetDomainById(ctx, domainId)
+	domain, err := r.client.GetDomainById(ctx, state.Id.ValueString())
 	if err != nil {
-		return diag.FromErr(err)
+		resp.Diagnostics.Append(diagFromErr(err)...)
+		return
 	}
 
-	if err = d.Set("unicode_name", resp.DnsDomain.Name); err != nil {
-		result = multierror.Append(result, err)
-	}
-	if err = d.Set("protected", resp.DnsDomain.Protected); err != nil {
-		result = multierror.Append(result, err)
+	resp.Diagnostics.Append(state.setDomain(ctx, &domain.DnsDomain)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	if err = d.Set("replication_type", resp.DnsDomain.ReplicationType); err != nil {
-		result = multierror.Append(result, err)
-	}
-	if err = d.Set("replication_master_ip_address_list", resp.DnsDomain.ReplicationMasterIpAddressList); err != nil {
-		result = multierror.Append(result, err)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *domainResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan domainResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	if err = d.Set("contract_id", resp.DnsDomain.ContractId); err != nil {
-		result = multierror.Append(result, err)
+	timeout, diags := plan.Timeouts.Update(ctx, 10*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	if result != nil {
-		return diag.FromErr(result)
+	var ips []string
+	resp.Diagnostics.Append(plan.ReplicationMasterIpAddressList.ElementsAs(ctx, &ips, false)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
+	domain, err := r.client.UpdateDomain(ctx, plan.Id.ValueString(), dns.NewUpdateDomain(dns.DomainUpdate{
+		ReplicationMasterIpAddressList: ips,
+		Protected:                      plan.Protected.ValueBool(),
+		CompanyId:                      plan.CompanyId.ValueString(),
+		ContractId:                     plan.ContractId.ValueString(),
+	}))
+	if err != nil {
+		resp.Diagnostics.Append(diagFromErr(err)...)
+		return
+	}
 
-	return diags
+	plan.Id = types.StringValue(strconv.Itoa(domain.DnsDomain.DnsDomainId))
+	plan.DomainId = types.Int64Value(int64(domain.DnsDomain.DnsDomainId))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 }
 
-func resourceDomainCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*dns.Client)
-	var diags diag.Diagnostics
-
-	unicodeName := d.Get("unicode_name").(string)
-	companyId := d.Get("company_id").(string)
-	protected := d.Get("protected").(bool)
-	replicationType := d.Get("replication_type").(string)
-	contractId := d.Get("contract_id").(string)
-	dnsNameserverPairName := d.Get("dns_nameserver_pair_name").(string)
-
-	replicationMasterIpAddressList := d.Get("replication_master_ip_address_list").([]interface{})
-
-	var ips []string
-	for _, ipAddress := range replicationMasterIpAddressList {
-		elem := ipAddress.(string)
-		ips = append(ips, elem)
-	}
-
-	data := dns.CreateDomain{DnsDomain: struct {
-		UnicodeName                    string   `json:"unicodeName"`
-		CompanyId                      string   `json:"companyId"`
-		DnsNameserverPairName          string   `json:"dnsNameserverPairName"`
-		Protected                      bool     `json:"protected"`
-		ReplicationType                string   `json:"replicationType"`
-		ReplicationMasterIpAddressList []string `json:"replicationMasterIpAddressList"`
-		ContractId                     string   `json:"contractId"`
-	}(struct {
-		UnicodeName                    string
-		CompanyId                      string
-		DnsNameserverPairName          string
-		Protected                      bool
-		ReplicationType                string
-		ReplicationMasterIpAddressList []string
-		ContractId                     string
-	}{UnicodeName: unicodeName, CompanyId: companyId, DnsNameserverPairName: dnsNameserverPairName, Protected: protected,
-		ReplicationType: replicationType, ReplicationMasterIpAddressList: ips, ContractId: contractId})}
-
-	resp, err := client.CreateDomain(ctx, &data)
-	if err != nil {
-		return diag.FromErr(err)
+func (r *domainResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state domainResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-
-	err = d.Set("domain_id", resp.DnsDomain.DnsDomainId)
-	if err != nil {
-		return diag.FromErr(err)
+	timeout, diags := state.Timeouts.Delete(ctx, 10*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	d.SetId(strconv.Itoa(resp.DnsDomain.DnsDomainId))
+	if _, err := r.client.DeleteDomain(ctx, state.Id.ValueString()); err != nil {
+		resp.Diagnostics.Append(diagFromErr(err)...)
+	}
+}
 
+// setDomain copies the attributes returned by the api into the model.
+func (m *domainResourceModel) setDomain(ctx context.Context, domain *dns.Domain) diag.Diagnostics {
+	ips, diags := types.ListValueFrom(ctx, types.StringType, domain.ReplicationMasterIpAddressList)
+	if diags.HasError() {
+		return diags
+	}
 
+	m.Id = types.StringValue(strconv.Itoa(domain.DnsDomainId))
+	m.DomainId = types.Int64Value(int64(domain.DnsDomainId))
+	m.UnicodeName = types.StringValue(domain.Name)
+	m.Protected = types.BoolValue(domain.Protected)
+	m.ReplicationType = types.StringValue(domain.ReplicationType)
+	if domain.DnsNameserverPairName != "" {
+		m.DnsNameserverPairName = types.StringValue(domain.DnsNameserverPairName)
+	}
+	m.ReplicationMasterIpAddressList = ips
+	m.ContractId = stringValueOrNull(domain.ContractId)
+	m.CreatedAt = types.StringValue(domain.CreateDateTime.String())
 	return diags
 }
@@ -2,246 +2,712 @@ package plusserver
 
 import (
 	"context"
-	"errors"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/plusserver/terraform-provider-plusserver/api/dns"
 	"strconv"
 	"strings"
+	"time"
 )
 
-func resourceDomainRecord() *schema.Resource {
-	return &schema.Resource{
-		Description: "Use the plusserver DNS API to create/modify/delete a domain record.",
-		CreateContext: resourceDomainRecordCreate,
-		ReadContext:   resourceDomainRecordRead,
-		UpdateContext: resourceDomainRecordUpdate,
-		DeleteContext: resourceDomainRecordDelete,
-		Importer: &schema.ResourceImporter{
-			StateContext: resourceDomainRecordImport,
-		},
+var (
+	_ resource.ResourceWithConfigure    = (*domainRecordResource)(nil)
+	_ resource.ResourceWithImportState  = (*domainRecordResource)(nil)
+	_ resource.ResourceWithModifyPlan   = (*domainRecordResource)(nil)
+	_ resource.ResourceWithUpgradeState = (*domainRecordResource)(nil)
+)
 
-		Schema: map[string]*schema.Schema{
-			"domain_id": {
-				Type: schema.TypeInt,
-				Description: "Exported ID of the domain record. Same as \"id\"",
-				Required: true,
+type domainRecordResource struct {
+	client   dns.API
+	defaults *providerDefaults
+}
+
+type domainRecordResourceModel struct {
+	Id             types.String   `tfsdk:"id"`
+	DomainId       types.Int64    `tfsdk:"domain_id"`
+	Name           types.String   `tfsdk:"name"`
+	Type           types.String   `tfsdk:"type"`
+	Ttl            types.Int64    `tfsdk:"ttl"`
+	Content        types.String   `tfsdk:"content"`
+	Fqdn           types.String   `tfsdk:"fqdn"`
+	AllowOverwrite types.Bool     `tfsdk:"allow_overwrite"`
+	Timeouts       timeouts.Value `tfsdk:"timeouts"`
+}
+
+// domainRecordResourceModelV0 is the state of schema version 0, which used the record id as resource id.
+type domainRecordResourceModelV0 struct {
+	Id       types.String   `tfsdk:"id"`
+	DomainId types.Int64    `tfsdk:"domain_id"`
+	Name     types.String   `tfsdk:"name"`
+	Type     types.String   `tfsdk:"type"`
+	Ttl      types.Int64    `tfsdk:"ttl"`
+	Content  types.String   `tfsdk:"content"`
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
+}
+
+func newDomainRecordResource() resource.Resource {
+	return &domainRecordResource{}
+}
+
+func (r *domainRecordResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_domain_record"
+}
+
+func (r *domainRecordResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Use the plusserver DNS API to create/modify/delete a domain record.",
+		Version:     1,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The ID of this resource in the form domainId:recordId",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
-			"name": {
-				Type: schema.TypeString,
-				Description: "Domain record name without TLD or second-level domain",
+			"domain_id": schema.Int64Attribute{
+				Description: "ID of the domain the record belongs to. Changing it replaces the record",
+				Required:    true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Description: "Domain record name. Either relative to the domain like www, @ for the apex of the domain or " +
+					"fully qualified like www.example.com. Wildcard records use * as leftmost label like *.example.com. " +
+					"Renaming a record creates the record with the new name before the record with the old name is deleted",
 				Required: true,
 			},
-			"type": {
-				Type: schema.TypeString,
-				Default: "A",
+			"type": schema.StringAttribute{
 				Description: "Domain record type. Can be either one of \"A\", \"AAAA\", \"CAA\", \"CNAME\", \"MX\", \"NS\", \"PTR\", \"SRV\", \"TXT\" or \"SOA\"." +
 					"The default Value is \"A\".",
-				ForceNew: true,
-				ValidateFunc: validation.StringInSlice([]string{"A", "AAAA", "CAA", "CNAME", "MX", "NS", "PTR", "SRV", "TXT", "SOA"}, false),
 				Optional: true,
+				Computed: true,
+				Default:  stringdefault.StaticString("A"),
+				Validators: []validator.String{
+					stringvalidator.OneOf("A", "AAAA", "CAA", "CNAME", "MX", "NS", "PTR", "SRV", "TXT", "SOA"),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
-			"ttl": {
-				Type: schema.TypeInt,
-				Description: "Domain record time to live in seconds. The default value is 300 seconds",
-				Default: 300,
+			"ttl": schema.Int64Attribute{
+				Description: "Domain record time to live in seconds. The default value is the default_ttl of the provider " +
+					"or 300 seconds",
 				Optional: true,
+				Computed: true,
 			},
-			"content": {
-				Type: schema.TypeString,
+			"content": schema.StringAttribute{
 				Description: "Domain record content. For example the IP address of the A record",
-				Required: true,
+				Required:    true,
+			},
+			"fqdn": schema.StringAttribute{
+				Description: "Fully qualified name of the record without trailing dot",
+				Computed:    true,
+			},
+			"allow_overwrite": schema.BoolAttribute{
+				Description: "Adopt an existing record with the same name and type when the record is created instead of " +
+					"creating another one. Its content and ttl are updated in place. The default value is false",
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(false),
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Update: true,
+				Delete: true,
+			}),
+		},
 	}
 }
 
-func resourceDomainRecordImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	parts := strings.SplitN(d.Id(), ":", 2)
-	client := meta.(*dns.Client)
-	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
-		return []*schema.ResourceData{}, fmt.Errorf("unexpected format of ID (%s), expected domainId:recordId", parts)
+func (r *domainRecordResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		// version 0 stored the record id as resource id, version 1 stores domainId:recordId
+		0: {
+			PriorSchema: &schema.Schema{
+				Attributes: map[string]schema.Attribute{
+					"id":        schema.StringAttribute{Computed: true},
+					"domain_id": schema.Int64Attribute{Required: true},
+					"name":      schema.StringAttribute{Required: true},
+					"type":      schema.StringAttribute{Optional: true, Computed: true},
+					"ttl":       schema.Int64Attribute{Optional: true, Computed: true},
+					"content":   schema.StringAttribute{Required: true},
+				},
+				Blocks: map[string]schema.Block{
+					"timeouts": timeouts.Block(ctx, timeouts.Opts{
+						Create: true,
+						Update: true,
+						Delete: true,
+					}),
+				},
+			},
+			StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+				var prior domainRecordResourceModelV0
+				resp.Diagnostics.Append(req.State.Get(ctx, &prior)...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+
+				resp.Diagnostics.Append(resp.State.Set(ctx, &domainRecordResourceModel{
+					Id:       types.StringValue(recordResourceId(int(prior.DomainId.ValueInt64()), prior.Id.ValueString())),
+					DomainId: prior.DomainId,
+					Name:     prior.Name,
+					Type:     prior.Type,
+					Ttl:      prior.Ttl,
+					Content:  prior.Content,
+					Timeouts: prior.Timeouts,
+				})...)
+			},
+		},
 	}
+}
 
-	// Split ID separate by domainId and recordId
-	domainId, convErr := strconv.Atoi(parts[0])
-	convErr = d.Set("domain_id", domainId)
-	if convErr != nil {
-		return []*schema.ResourceData{}, convErr
+func (r *domainRecordResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	meta, err := metaFromProviderData(req.ProviderData)
+	if err != nil {
+		resp.Diagnostics.AddError("Unexpected provider data", err.Error())
+		return
 	}
-	// Set recordId to resource
-	d.SetId(parts[1])
+	if meta != nil {
+		r.client = meta.dns
+		r.defaults = &meta.defaults
+	}
+}
 
-	// Get all records in rrset
-	records, err := client.GetRecords(ctx, domainId)
-	if err != nil {
-		return []*schema.ResourceData{}, err
+// ModifyPlan applies the default ttl, checks that the name is part of the domain, computes the fqdn and checks the
+// record against the records of the domain. The checks only run if the record is created or its name, type or
+// content changes.
+func (r *domainRecordResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
 	}
-	// Get record by Id
-	domainRecord := getRecordResourceById(d.Id(), records.DnsResourceRecordList)
-	if domainRecord == nil {
-		return []*schema.ResourceData{}, errors.New("could not find record by id in rrset domain")
+	// the default ttl is unknown until the provider is configured
+	if r.defaults != nil {
+		var ttl types.Int64
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("ttl"), &ttl)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if ttl.IsNull() {
+			defaultTtl := 300
+			if r.defaults.ttl > 0 {
+				defaultTtl = r.defaults.ttl
+			}
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("ttl"), defaultTtl)...)
+		}
 	}
-	err = d.Set("content", domainRecord.Content)
-	err = d.Set("name", domainRecord.Name)
-	err = d.Set("ttl", domainRecord.Ttl)
-	err = d.Set("type", domainRecord.Type)
-	if err != nil {
-		return []*schema.ResourceData{}, err
+	if resp.Diagnostics.HasError() || r.client == nil {
+		return
 	}
 
-	return []*schema.ResourceData{d}, nil
-}
-
-func resourceDomainRecordUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*dns.Client)
-	var diags diag.Diagnostics
+	var plan domainRecordResourceModel
+	resp.Diagnostics.Append(resp.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() || plan.DomainId.IsUnknown() || plan.Name.IsUnknown() || plan.Type.IsUnknown() {
+		return
+	}
+	recordId := ""
+	if !req.State.Raw.IsNull() {
+		var state domainRecordResourceModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if plan.DomainId.Equal(state.DomainId) && plan.Name.Equal(state.Name) && plan.Type.Equal(state.Type) &&
+			plan.Content.Equal(state.Content) && !state.Fqdn.IsNull() {
+			// the name still resolves to the same fqdn, changes of ttl or allow_overwrite must not make it unknown
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("fqdn"), state.Fqdn)...)
+			return
+		}
+		_, recordId, _ = parseRecordResourceId(state.Id.ValueString())
+	}
 
-	content := d.Get("content").(string)
-	domainId := d.Get("domain_id").(int)
-	name := d.Get("name").(string)
-	ttl := d.Get("ttl").(int)
+	domainId := int(plan.DomainId.ValueInt64())
+	domain, diags := r.domainName(ctx, domainId)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	name, err := relativeRecordName(plan.Name.ValueString(), domain)
+	if err == nil {
+		err = validateRecordName(name)
+	}
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("name"), "Invalid record name", err.Error())
+		return
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("fqdn"), recordFqdn(name, domain))...)
 
-	_, err := client.UpdateRecord(ctx, domainId, d.Id(), content, ttl)
+	records, err := r.client.GetRecords(ctx, domainId)
 	if err != nil {
-		return diag.FromErr(err)
+		resp.Diagnostics.Append(diagFromErr(err)...)
+		return
 	}
+	record := &dns.Record{DnsDomainId: domainId, Name: name, Type: plan.Type.ValueString(),
+		Content: plan.Content.ValueString()}
+	ignoredIds := []string{recordId}
+	switch {
+	case recordId == "" && plan.AllowOverwrite.ValueBool():
+		adopted, err := findAdoptedRecord(records.DnsResourceRecordList, record)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("allow_overwrite"), "Unable to adopt record", err.Error())
+			return
+		}
+		if adopted != nil {
+			detail := fmt.Sprintf("the record %s with the same name and type exists already and is overwritten",
+				adopted.DnsResourceRecordId)
+			if adopted.Content == record.Content {
+				detail = fmt.Sprintf("the record %s with the same name, type and content exists already and is adopted",
+					adopted.DnsResourceRecordId)
+			}
+			resp.Diagnostics.AddAttributeWarning(path.Root("allow_overwrite"), "Existing record is adopted", detail)
+			ignoredIds = append(ignoredIds, adopted.DnsResourceRecordId)
+		}
+	case !plan.Content.IsUnknown():
+		duplicate, err := findRecord(records.DnsResourceRecordList, "", recordFingerprint(record))
+		if err == nil && duplicate != nil && duplicate.DnsResourceRecordId == recordId {
+			duplicate = nil
+		}
+		switch {
+		case err != nil:
+			resp.Diagnostics.AddAttributeError(path.Root("name"), "Duplicate record", err.Error())
+			return
+		case duplicate != nil && recordId == "":
+			resp.Diagnostics.AddAttributeError(path.Root("name"), "Duplicate record",
+				fmt.Sprintf("the record %s with the same name, type and content exists already. Import it or set "+
+					"allow_overwrite to adopt it", duplicate.DnsResourceRecordId))
+			return
+		case duplicate != nil:
+			resp.Diagnostics.AddAttributeError(path.Root("name"), "Duplicate record",
+				fmt.Sprintf("the record %s with the same name, type and content exists already",
+					duplicate.DnsResourceRecordId))
+			return
+		}
+	}
+	if err = checkRecordConflicts(records.DnsResourceRecordList, record, ignoredIds...); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("name"), "Conflicting record", err.Error())
+	}
+}
 
-	// scan again to get the updated record_id
-	records, err := client.GetRecords(ctx, domainId)
+func (r *domainRecordResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	domainId, _, err := parseRecordResourceId(req.ID)
 	if err != nil {
-		return diag.FromErr(err)
+		resp.Diagnostics.AddError("Invalid import ID", err.Error())
+		return
 	}
 
-	recordId := getRecordResourceID(name, content, records.DnsResourceRecordList)
+	// the remaining attributes are set by Read
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("domain_id"), domainId)...)
+}
+
+func (r *domainRecordResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan domainRecordResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	timeout, diags := plan.Timeouts.Create(ctx, 10*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	domainId := int(plan.DomainId.ValueInt64())
+	domain, diags := r.domainName(ctx, domainId)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	record, diags := plan.record(domainId, domain)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var recordId string
+	if plan.AllowOverwrite.ValueBool() {
+		recordId, diags = r.adoptRecord(ctx, record)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
 	if recordId == "" {
-		//NOTE: If this happens we are all screwed since I can't remove the record because I don't have the ID
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "Unable to save resource id",
-			Detail:   "the created record was not found in the domain",
-		})
-		return diags
+		recordId, diags = r.createRecord(ctx, record)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
 	}
 
-	d.SetId(recordId)
-
-	return diags
+	plan.Id = types.StringValue(recordResourceId(domainId, recordId))
+	plan.Fqdn = types.StringValue(recordFqdn(record.Name, domain))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 }
 
-func resourceDomainRecordDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*dns.Client)
+// adoptRecord takes over the existing record found by findAdoptedRecord and updates its content and ttl in place.
+// An empty id is returned if there is no such record.
+func (r *domainRecordResource) adoptRecord(ctx context.Context, record *dns.Record) (string, diag.Diagnostics) {
 	var diags diag.Diagnostics
 
-	domainId := d.Get("domain_id").(int)
-
-	_, err := client.DeleteRecord(ctx, domainId, d.Id())
+	records, err := r.client.GetRecords(ctx, record.DnsDomainId)
 	if err != nil {
-		return diag.FromErr(err)
+		return "", diagFromErr(err)
+	}
+	existing, err := findAdoptedRecord(records.DnsResourceRecordList, record)
+	if err != nil {
+		diags.AddError("Unable to adopt record", err.Error())
+		return "", diags
+	}
+	if existing == nil {
+		return "", diags
 	}
 
-	// implied but we explicitly set it here
-	d.SetId("")
-
-	return diags
+	tflog.Info(ctx, "adopting existing record", map[string]interface{}{
+		"record_id": existing.DnsResourceRecordId,
+	})
+	if existing.Content == record.Content && existing.Ttl == record.Ttl {
+		return existing.DnsResourceRecordId, diags
+	}
+	if _, err = r.client.UpdateRecord(ctx, record.DnsDomainId, existing.DnsResourceRecordId, record.Content, record.Ttl); err != nil {
+		return "", diagFromErr(err)
+	}
+	// the api may reissue the id on updates
+	if records, err = r.client.GetRecords(ctx, record.DnsDomainId); err != nil {
+		return "", diagFromErr(err)
+	}
+	updated, err := findRecord(records.DnsResourceRecordList, existing.DnsResourceRecordId, recordFingerprint(record))
+	if err != nil || updated == nil {
+		diags.AddError("Unable to adopt record", fmt.Sprintf("the record %s was not found after updating it",
+			existing.DnsResourceRecordId))
+		return "", diags
+	}
+	return updated.DnsResourceRecordId, diags
 }
 
-func resourceDomainRecordRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*dns.Client)
+// createRecord creates the record and returns the id assigned by the api.
+func (r *domainRecordResource) createRecord(ctx context.Context, record *dns.Record) (string, diag.Diagnostics) {
 	var diags diag.Diagnostics
 
-	content := d.Get("content").(string)
-	domainId := d.Get("domain_id").(int)
-	name := d.Get("name").(string)
+	created, err := r.client.CreateRecord(ctx, dns.NewRecordCreateRequest(*record))
+	if err != nil {
+		return "", diagFromErr(err)
+	}
+
+	// the created records are taken from the response if the api returns them, the zone is listed otherwise
+	found, err := findRecord(created.DnsResourceRecordList, "", recordFingerprint(record))
+	if err == nil && found == nil {
+		var records *dns.RecordsResponse
+		if records, err = r.client.GetRecords(ctx, record.DnsDomainId); err != nil {
+			return "", diagFromErr(err)
+		}
+		found, err = findRecord(records.DnsResourceRecordList, "", recordFingerprint(record))
+	}
+	if err != nil {
+		diags.AddError("Unable to save resource id", err.Error())
+		return "", diags
+	}
+	if found == nil {
+		diags.AddError("Unable to save resource id", "the created record was not found in the domain")
+		return "", diags
+	}
+	return found.DnsResourceRecordId, diags
+}
 
-	records, err := client.GetRecords(ctx, domainId)
+// Read looks the record up by its id. If the id is gone, the record is looked up by its fingerprint, since the api
+// reissues record ids. The record is removed from the state if neither matches.
+func (r *domainRecordResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state domainRecordResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
+	domainId, recordId, err := parseRecordResourceId(state.Id.ValueString())
 	if err != nil {
-		return diag.FromErr(err)
+		resp.Diagnostics.AddError("Invalid resource ID", err.Error())
+		return
+	}
+	domainResp, err := r.client.GetDomainById(ctx, strconv.Itoa(domainId))
+	if isNotFound(err) {
+		// the records of a deleted domain are gone with it
+		tflog.Warn(ctx, "domain not found, removing the record from the state", map[string]interface{}{
+			"id": state.Id.ValueString(),
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.Append(diagFromErr(err)...)
+		return
+	}
+	domain := domainResp.DnsDomain.Name
+	records, err := r.client.GetRecords(ctx, domainId)
+	if err != nil {
+		resp.Diagnostics.Append(diagFromErr(err)...)
+		return
 	}
 
-	recordId := getRecordResourceID(name, content, records.DnsResourceRecordList)
-	if recordId == "" {
-		//NOTE: If this happens we are all screwed since I can't remove the record because I don't have the ID
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "Unable to save resource id",
-			Detail:   "the created record was not found in the domain",
+	// the fingerprint is only needed if the record id is gone, a name outside of the domain can't match then
+	fingerprint := ""
+	if stateRecord, diags := state.record(domainId, domain); !diags.HasError() {
+		fingerprint = recordFingerprint(stateRecord)
+	}
+	record, err := findRecord(records.DnsResourceRecordList, recordId, fingerprint)
+	if err != nil {
+		resp.Diagnostics.AddError("Unable to find record", err.Error())
+		return
+	}
+	if record == nil {
+		tflog.Warn(ctx, "record not found, removing it from the state", map[string]interface{}{
+			"id": state.Id.ValueString(),
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if record.DnsResourceRecordId != recordId {
+		tflog.Info(ctx, "record id has been changed by the api", map[string]interface{}{
+			"id":            state.Id.ValueString(),
+			"new_record_id": record.DnsResourceRecordId,
 		})
-		return diags
 	}
 
-	return diags
+	state.Id = types.StringValue(recordResourceId(domainId, record.DnsResourceRecordId))
+	state.DomainId = types.Int64Value(int64(domainId))
+	// the name is kept as configured as long as it refers to the same record
+	if name, err := relativeRecordName(state.Name.ValueString(), domain); state.Name.IsNull() || err != nil ||
+		!strings.EqualFold(name, record.Name) {
+		state.Name = types.StringValue(record.Name)
+	}
+	state.Fqdn = types.StringValue(recordFqdn(record.Name, domain))
+	if state.AllowOverwrite.IsNull() {
+		// imported records and states of earlier versions have no value
+		state.AllowOverwrite = types.BoolValue(false)
+	}
+	state.Type = types.StringValue(record.Type)
+	state.Ttl = types.Int64Value(int64(record.Ttl))
+	state.Content = types.StringValue(record.Content)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
+func (r *domainRecordResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state domainRecordResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	timeout, diags := plan.Timeouts.Update(ctx, 10*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-func resourceDomainRecordCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*dns.Client)
-	var diags diag.Diagnostics
+	domainId := int(plan.DomainId.ValueInt64())
+	_, recordId, err := parseRecordResourceId(state.Id.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid resource ID", err.Error())
+		return
+	}
 
-	content := d.Get("content").(string)
-	domainId := d.Get("domain_id").(int)
-	dnsType := d.Get("type").(string)
-	name := d.Get("name").(string)
-	ttl := d.Get("ttl").(int)
+	domain, diags := r.domainName(ctx, domainId)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	record, diags := plan.record(domainId, domain)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	plan.Fqdn = types.StringValue(recordFqdn(record.Name, domain))
 
-	_, err := client.CreateRecord(ctx, &dns.RecordCreateRequest{DnsResourceRecordList: []struct {
-		Content     string `json:"content"`
-		DnsDomainId int    `json:"dnsDomainId"`
-		Type        string `json:"type"`
-		Name        string `json:"name"`
-		Ttl         int    `json:"ttl"`
-	}{{content, domainId, dnsType, name, ttl}}})
+	// names referring to the same record in different notations don't need a rename
+	if oldName, err := relativeRecordName(state.Name.ValueString(), domain); err != nil || oldName != record.Name {
+		r.rename(ctx, &plan, record, recordId, resp)
+		return
+	}
 
+	_, err = r.client.UpdateRecord(ctx, domainId, recordId, plan.Content.ValueString(), int(plan.Ttl.ValueInt64()))
 	if err != nil {
-		return diag.FromErr(err)
+		resp.Diagnostics.Append(diagFromErr(err)...)
+		return
+	}
+
+	// The api may have reissued the record id. Until the new id is known the planned attributes are stored with
+	// the last known id, so Read can still find the record by its fingerprint if the lookup below fails.
+	plan.Id = types.StringValue(recordResourceId(domainId, recordId))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	records, err := client.GetRecords(ctx, domainId)
+	records, err := r.client.GetRecords(ctx, domainId)
+	if err != nil {
+		resp.Diagnostics.Append(diagFromErr(err)...)
+		return
+	}
+	updated, err := findRecord(records.DnsResourceRecordList, recordId, recordFingerprint(record))
 	if err != nil {
-		return diag.FromErr(err)
+		resp.Diagnostics.AddError("Unable to find updated record", err.Error())
+		return
+	}
+	if updated == nil {
+		resp.Diagnostics.AddError("Unable to find updated record", "the updated record was not found in the domain")
+		return
 	}
 
-	recordId := getRecordResourceID(name, content, records.DnsResourceRecordList)
-	if recordId == "" {
-		//NOTE: If this happens we are all screwed since I can't remove the stupid record because I don't have the ID
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "Unable to save resource id",
-			Detail:   "the created record was not found in the domain",
-		})
-		return diags
+	plan.Id = types.StringValue(recordResourceId(domainId, updated.DnsResourceRecordId))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// rename replaces the record with recordId by a record with the planned name. The update endpoint only accepts
+// content and ttl, so the new record is created before the old one is deleted. If the old record can't be
+// deleted, the state keeps track of the new record and the old record id is reported.
+func (r *domainRecordResource) rename(ctx context.Context, plan *domainRecordResourceModel, record *dns.Record, recordId string, resp *resource.UpdateResponse) {
+	domainId := record.DnsDomainId
+	newRecordId, diags := r.createRecord(ctx, record)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	d.SetId(recordId)
+	plan.Id = types.StringValue(recordResourceId(domainId, newRecordId))
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	return diags
+	if _, err := r.client.DeleteRecord(ctx, domainId, recordId); err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("Unable to delete the record %s after renaming it", recordId),
+			fmt.Sprintf("The record has been created with the new name as %s, but the record with the old name "+
+				"could not be deleted and has to be deleted manually: %s", newRecordId, err))
+	}
 }
 
-func getRecordResourceID(name string, content string, items []*dns.RecordsResponseEntry) string {
-	if items != nil {
-		for _, recordItem := range items {
-			if recordItem.Name == name && recordItem.Content == content {
-				return recordItem.DnsResourceRecordId
-			}
-		}
-		return ""
+func (r *domainRecordResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state domainRecordResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
+	timeout, diags := state.Timeouts.Delete(ctx, 10*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	return ""
+	domainId, recordId, err := parseRecordResourceId(state.Id.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid resource ID", err.Error())
+		return
+	}
+	if _, err = r.client.DeleteRecord(ctx, domainId, recordId); err != nil {
+		resp.Diagnostics.Append(diagFromErr(err)...)
+	}
 }
 
-func getRecordResourceById(id string, items []*dns.RecordsResponseEntry) *dns.RecordsResponseEntry {
-	if items != nil {
-		for _, recordItem := range items {
-			if recordItem.DnsResourceRecordId == id {
-				return recordItem
-			}
-		}
-		return nil
+// recordResourceId returns the resource id of a record, which contains the domain id so the record can be found
+// from the id alone.
+func recordResourceId(domainId int, recordId string) string {
+	return fmt.Sprintf("%d:%s", domainId, recordId)
+}
+
+// parseRecordResourceId splits a resource id in the form domainId:recordId.
+func parseRecordResourceId(id string) (int, string, error) {
+	parts := strings.SplitN(id, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return 0, "", fmt.Errorf("unexpected format of ID (%s), expected domainId:recordId", id)
 	}
+	domainId, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, "", fmt.Errorf("the domain id of %s is not a number", id)
+	}
+	return domainId, parts[1], nil
+}
 
-	return nil
-}
\ No newline at end of file
+// domainName returns the name of the domain the record names are relative to.
+func (r *domainRecordResource) domainName(ctx context.Context, domainId int) (string, diag.Diagnostics) {
+	domain, err := r.client.GetDomainById(ctx, strconv.Itoa(domainId))
+	if err != nil {
+		return "", diagFromErr(err)
+	}
+	return domain.DnsDomain.Name, nil
+}
+
+// record returns the record described by the model with the name relative to domain.
+func (m *domainRecordResourceModel) record(domainId int, domain string) (*dns.Record, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	name, err := relativeRecordName(m.Name.ValueString(), domain)
+	if err == nil {
+		err = validateRecordName(name)
+	}
+	if err != nil {
+		diags.AddAttributeError(path.Root("name"), "Invalid record name", err.Error())
+		return nil, diags
+	}
+	return &dns.Record{
+		DnsDomainId: domainId,
+		Name:        name,
+		Type:        m.Type.ValueString(),
+		Content:     m.Content.ValueString(),
+		Ttl:         int(m.Ttl.ValueInt64()),
+	}, diags
+}
+
+// recordFingerprint identifies a record by its domain, name, type and content. The ttl is left out, records
+// with the same fingerprint only differ in their ttl. Names are compared case insensitively like in DNS.
+func recordFingerprint(record *dns.Record) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d\x00%s\x00%s\x00%s", record.DnsDomainId, strings.ToLower(record.Name),
+		record.Type, record.Content)))
+	return hex.EncodeToString(sum[:])
+}
+
+// findRecord returns the record with recordId or, if there is none, the record with the fingerprint. Nil is
+// returned if neither matches, an error if the fingerprint matches multiple records.
+func findRecord(records []*dns.Record, recordId string, fingerprint string) (*dns.Record, error) {
+	var matches []*dns.Record
+	for _, record := range records {
+		if recordId != "" && record.DnsResourceRecordId == recordId {
+			return record, nil
+		}
+		if recordFingerprint(record) == fingerprint {
+			matches = append(matches, record)
+		}
+	}
+	if len(matches) > 1 {
+		ids := make([]string, len(matches))
+		for i, match := range matches {
+			ids[i] = match.DnsResourceRecordId
+		}
+		return nil, fmt.Errorf("the records %s have the same name, type and content", strings.Join(ids, ", "))
+	}
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,920 @@
+package plusserver
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/plusserver/terraform-provider-plusserver/api"
+	"github.com/plusserver/terraform-provider-plusserver/api/dns"
+	"github.com/plusserver/terraform-provider-plusserver/api/dns/mock"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestParseRecordResourceId(t *testing.T) {
+	tests := []struct {
+		id           string
+		wantDomainId int
+		wantRecordId string
+		wantErr      bool
+	}{
+		{id: "1:10", wantDomainId: 1, wantRecordId: "10"},
+		{id: "1:a:b", wantDomainId: 1, wantRecordId: "a:b"},
+		{id: "10", wantErr: true},
+		{id: ":10", wantErr: true},
+		{id: "1:", wantErr: true},
+		{id: "example.com:10", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			domainId, recordId, err := parseRecordResourceId(tt.id)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRecordResourceId() error = %v, want error %v", err, tt.wantErr)
+			}
+			if domainId != tt.wantDomainId || recordId != tt.wantRecordId {
+				t.Errorf("parseRecordResourceId() = %d, %q, want %d, %q", domainId, recordId, tt.wantDomainId,
+					tt.wantRecordId)
+			}
+		})
+	}
+}
+
+func TestFindRecord(t *testing.T) {
+	records := []*dns.Record{
+		{DnsResourceRecordId: "1", DnsDomainId: 1, Name: "www", Type: "A", Content: "10.0.0.1"},
+		{DnsResourceRecordId: "2", DnsDomainId: 1, Name: "MAIL", Type: "A", Content: "10.0.0.2"},
+		{DnsResourceRecordId: "3", DnsDomainId: 1, Name: "dup", Type: "TXT", Content: "hello"},
+		{DnsResourceRecordId: "4", DnsDomainId: 1, Name: "dup", Type: "TXT", Content: "hello"},
+	}
+	fingerprint := func(name string, recordType string, content string) string {
+		return recordFingerprint(&dns.Record{DnsDomainId: 1, Name: name, Type: recordType, Content: content})
+	}
+
+	tests := []struct {
+		name        string
+		recordId    string
+		fingerprint string
+		want        string
+		wantErr     bool
+	}{
+		{name: "by id", recordId: "2", fingerprint: fingerprint("www", "A", "10.0.0.1"), want: "2"},
+		{name: "by fingerprint", recordId: "9", fingerprint: fingerprint("www", "A", "10.0.0.1"), want: "1"},
+		{name: "by fingerprint in other case", fingerprint: fingerprint("mail", "A", "10.0.0.2"), want: "2"},
+		{name: "id before ambiguous fingerprint", recordId: "4", fingerprint: fingerprint("dup", "TXT", "hello"), want: "4"},
+		{name: "ambiguous fingerprint", recordId: "9", fingerprint: fingerprint("dup", "TXT", "hello"), wantErr: true},
+		{name: "other content", recordId: "9", fingerprint: fingerprint("www", "A", "10.0.0.9")},
+		{name: "other domain", recordId: "9", fingerprint: recordFingerprint(&dns.Record{DnsDomainId: 2, Name: "www",
+			Type: "A", Content: "10.0.0.1"})},
+		{name: "no fingerprint", recordId: "9"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := findRecord(records, tt.recordId, tt.fingerprint)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("findRecord() error = %v, want error %v", err, tt.wantErr)
+			}
+			if id := recordId(got); id != tt.want {
+				t.Errorf("findRecord() = %q, want %q", id, tt.want)
+			}
+		})
+	}
+}
+
+func TestDomainRecordCreate(t *testing.T) {
+	ctx := context.Background()
+	s := recordSchema(ctx)
+	var created *dns.RecordCreateRequest
+	r := &domainRecordResource{client: &mock.API{
+		GetDomainByIdFunc: getExampleDomain,
+		CreateRecordFunc: func(ctx context.Context, records *dns.RecordCreateRequest) (*dns.RecordsResponse, error) {
+			created = records
+			return &dns.RecordsResponse{}, nil
+		},
+		GetRecordsFunc: func(ctx context.Context, domainId int) (*dns.RecordsResponse, error) {
+			return &dns.RecordsResponse{DnsResourceRecordList: []*dns.Record{
+				{DnsResourceRecordId: "10", DnsDomainId: 1, Name: "www", Type: "A", Content: "192.0.2.2", Ttl: 300},
+				{DnsResourceRecordId: "11", DnsDomainId: 1, Name: "www", Type: "A", Content: "192.0.2.1", Ttl: 300},
+			}}, nil
+		},
+	}}
+
+	plan := modelState(t, ctx, s, domainRecordResourceModel{
+		Id:       types.StringUnknown(),
+		DomainId: types.Int64Value(1),
+		Name:     types.StringValue("www"),
+		Type:     types.StringValue("A"),
+		Ttl:      types.Int64Value(300),
+		Content:  types.StringValue("192.0.2.1"),
+		Timeouts: nullTimeouts(),
+	})
+	resp := &resource.CreateResponse{State: emptyState(ctx, s)}
+	r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Schema: s, Raw: plan.Raw}}, resp)
+	assertNoDiags(t, resp.Diagnostics)
+
+	var got domainRecordResourceModel
+	assertNoDiags(t, resp.State.Get(ctx, &got))
+	if got.Id.ValueString() != "1:11" {
+		t.Errorf("id = %s, want the id of the created record", got.Id)
+	}
+	if got.Fqdn.ValueString() != "www.example.com" {
+		t.Errorf("fqdn = %s, want www.example.com", got.Fqdn)
+	}
+	want := dns.Record{DnsDomainId: 1, Name: "www", Type: "A", Content: "192.0.2.1", Ttl: 300}
+	if created == nil || len(created.DnsResourceRecordList) != 1 || created.DnsResourceRecordList[0] != want {
+		t.Errorf("created %v, want %v", created, want)
+	}
+}
+
+func TestDomainRecordAdopt(t *testing.T) {
+	tests := []struct {
+		name       string
+		existing   *dns.Record
+		wantId     string
+		wantWrites []string
+	}{
+		{
+			name:     "same record",
+			existing: &dns.Record{DnsResourceRecordId: "10", DnsDomainId: 1, Name: "www", Type: "A", Content: "10.0.0.1", Ttl: 600},
+			wantId:   "1:10",
+		},
+		{
+			name:       "other ttl",
+			existing:   &dns.Record{DnsResourceRecordId: "10", DnsDomainId: 1, Name: "www", Type: "A", Content: "10.0.0.1", Ttl: 300},
+			wantId:     "1:10",
+			wantWrites: []string{"UpdateRecord"},
+		},
+		{
+			name:       "other content",
+			existing:   &dns.Record{DnsResourceRecordId: "10", DnsDomainId: 1, Name: "www", Type: "A", Content: "10.0.0.9", Ttl: 600},
+			wantId:     "1:10",
+			wantWrites: []string{"UpdateRecord"},
+		},
+		{
+			name:       "no existing record",
+			existing:   &dns.Record{DnsResourceRecordId: "10", DnsDomainId: 1, Name: "www", Type: "AAAA", Content: "::1", Ttl: 600},
+			wantId:     "1:20",
+			wantWrites: []string{"CreateRecord"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := recordSchema(ctx)
+			existing := *tt.existing
+			client := &mock.API{
+				GetDomainByIdFunc: getExampleDomain,
+				GetRecordsFunc: func(ctx context.Context, domainId int) (*dns.RecordsResponse, error) {
+					return &dns.RecordsResponse{DnsResourceRecordList: []*dns.Record{&existing}}, nil
+				},
+				UpdateRecordFunc: func(ctx context.Context, domainId int, recordId string, content string, ttl int) (*dns.RecordUpdateResponse, error) {
+					existing.Content = content
+					existing.Ttl = ttl
+					return &dns.RecordUpdateResponse{}, nil
+				},
+				CreateRecordFunc: func(ctx context.Context, records *dns.RecordCreateRequest) (*dns.RecordsResponse, error) {
+					created := records.DnsResourceRecordList[0]
+					created.DnsResourceRecordId = "20"
+					return &dns.RecordsResponse{DnsResourceRecordList: []*dns.Record{&created}}, nil
+				},
+			}
+			r := &domainRecordResource{client: client}
+
+			plan := modelState(t, ctx, s, domainRecordResourceModel{
+				Id:             types.StringUnknown(),
+				DomainId:       types.Int64Value(1),
+				Name:           types.StringValue("www"),
+				Type:           types.StringValue("A"),
+				Ttl:            types.Int64Value(600),
+				Content:        types.StringValue("10.0.0.1"),
+				Fqdn:           types.StringUnknown(),
+				AllowOverwrite: types.BoolValue(true),
+				Timeouts:       nullTimeouts(),
+			})
+			resp := &resource.CreateResponse{State: emptyState(ctx, s)}
+			r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan{Schema: s, Raw: plan.Raw}}, resp)
+			assertNoDiags(t, resp.Diagnostics)
+
+			var got domainRecordResourceModel
+			assertNoDiags(t, resp.State.Get(ctx, &got))
+			if got.Id.ValueString() != tt.wantId {
+				t.Errorf("id = %s, want %s", got.Id, tt.wantId)
+			}
+			var writes []string
+			for _, call := range writeCalls(client) {
+				writes = append(writes, call.Method)
+			}
+			if strings.Join(writes, ",") != strings.Join(tt.wantWrites, ",") {
+				t.Errorf("writes %v, want %v", writes, tt.wantWrites)
+			}
+		})
+	}
+}
+
+func TestDomainRecordImportState(t *testing.T) {
+	ctx := context.Background()
+	s := recordSchema(ctx)
+	r := &domainRecordResource{client: &mock.API{}}
+
+	tests := []struct {
+		id        string
+		wantError string
+	}{
+		{id: "1:11"},
+		{id: "11", wantError: "Invalid import ID"},
+		{id: "example.com:11", wantError: "Invalid import ID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			resp := &resource.ImportStateResponse{State: emptyState(ctx, s)}
+			r.ImportState(ctx, resource.ImportStateRequest{ID: tt.id}, resp)
+			if tt.wantError != "" {
+				assertDiag(t, resp.Diagnostics, diag.SeverityError, tt.wantError)
+				return
+			}
+			assertNoDiags(t, resp.Diagnostics)
+
+			// the remaining attributes are read by Read
+			var got domainRecordResourceModel
+			assertNoDiags(t, resp.State.Get(ctx, &got))
+			if got.Id.ValueString() != "1:11" || got.DomainId.ValueInt64() != 1 {
+				t.Errorf("imported id, domain_id = %s, %s, want 1:11, 1", got.Id, got.DomainId)
+			}
+		})
+	}
+}
+
+func TestDomainRecordModifyPlan(t *testing.T) {
+	existing := []*dns.Record{
+		{DnsResourceRecordId: "10", DnsDomainId: 1, Name: "WWW", Type: "A", Content: "10.0.0.1", Ttl: 300},
+		{DnsResourceRecordId: "11", DnsDomainId: 1, Name: "mail", Type: "A", Content: "10.0.0.2", Ttl: 300},
+	}
+	stored := domainRecordResourceModel{
+		Id:             types.StringValue("1:10"),
+		DomainId:       types.Int64Value(1),
+		Name:           types.StringValue("www"),
+		Type:           types.StringValue("A"),
+		Ttl:            types.Int64Value(300),
+		Content:        types.StringValue("10.0.0.1"),
+		Fqdn:           types.StringValue("www.example.com"),
+		AllowOverwrite: types.BoolValue(false),
+	}
+
+	tests := []struct {
+		name   string
+		state  *domainRecordResourceModel
+		config func(m *domainRecordResourceModel)
+		// defaultTtl is the default_ttl of the provider
+		defaultTtl  int
+		wantTtl     int64
+		wantFqdn    string
+		wantError   string
+		wantWarning string
+		wantCalls   bool
+	}{
+		{
+			name:   "ttl change keeps fqdn",
+			state:  &stored,
+			config: func(m *domainRecordResourceModel) { m.Ttl = types.Int64Value(600) },
+			// the unchanged record is not checked again
+			wantTtl:  600,
+			wantFqdn: "www.example.com",
+		},
+		{
+			name:     "allow_overwrite change keeps fqdn",
+			state:    &stored,
+			config:   func(m *domainRecordResourceModel) { m.AllowOverwrite = types.BoolValue(true) },
+			wantTtl:  300,
+			wantFqdn: "www.example.com",
+		},
+		{
+			name:       "removed ttl uses the default ttl",
+			state:      &stored,
+			config:     func(m *domainRecordResourceModel) { m.Ttl = types.Int64Null() },
+			defaultTtl: 3600,
+			wantTtl:    3600,
+			wantFqdn:   "www.example.com",
+		},
+		{
+			name:     "removed ttl without default ttl",
+			state:    &stored,
+			config:   func(m *domainRecordResourceModel) { m.Ttl = types.Int64Null() },
+			wantTtl:  300,
+			wantFqdn: "www.example.com",
+		},
+		{
+			name:      "rename computes the fqdn",
+			state:     &stored,
+			config:    func(m *domainRecordResourceModel) { m.Name = types.StringValue("web.example.com.") },
+			wantTtl:   300,
+			wantFqdn:  "web.example.com",
+			wantCalls: true,
+		},
+		{
+			name:       "new record",
+			config:     func(m *domainRecordResourceModel) { m.Name = types.StringValue("@"); m.Ttl = types.Int64Null() },
+			defaultTtl: 3600,
+			wantTtl:    3600,
+			wantFqdn:   "example.com",
+			wantCalls:  true,
+		},
+		{
+			name:      "duplicate of an existing record",
+			config:    func(m *domainRecordResourceModel) {},
+			wantError: "Duplicate record",
+			wantCalls: true,
+		},
+		{
+			name:        "adopted duplicate",
+			config:      func(m *domainRecordResourceModel) { m.AllowOverwrite = types.BoolValue(true) },
+			wantTtl:     300,
+			wantFqdn:    "www.example.com",
+			wantWarning: "Existing record is adopted",
+			wantCalls:   true,
+		},
+		{
+			name: "overwritten record",
+			config: func(m *domainRecordResourceModel) {
+				m.Content = types.StringValue("10.0.0.9")
+				m.AllowOverwrite = types.BoolValue(true)
+			},
+			wantTtl:     300,
+			wantFqdn:    "www.example.com",
+			wantWarning: "is overwritten",
+			wantCalls:   true,
+		},
+		{
+			name:  "rename to a duplicate",
+			state: &stored,
+			config: func(m *domainRecordResourceModel) {
+				m.Name = types.StringValue("mail")
+				m.Content = types.StringValue("10.0.0.2")
+			},
+			wantError: "Duplicate record",
+			wantCalls: true,
+		},
+		{
+			name:      "name outside of the domain",
+			config:    func(m *domainRecordResourceModel) { m.Name = types.StringValue("www.example.org.") },
+			wantError: "Invalid record name",
+			wantCalls: true,
+		},
+		{
+			name:      "wildcard inside the name",
+			config:    func(m *domainRecordResourceModel) { m.Name = types.StringValue("www.*") },
+			wantError: "Invalid record name",
+			wantCalls: true,
+		},
+		{
+			name: "CNAME next to an existing record",
+			config: func(m *domainRecordResourceModel) {
+				m.Type = types.StringValue("CNAME")
+				m.Content = types.StringValue("web")
+			},
+			wantError: "Conflicting record",
+			wantCalls: true,
+		},
+		{
+			name:  "type change to CNAME of the same record",
+			state: &stored,
+			config: func(m *domainRecordResourceModel) {
+				m.Type = types.StringValue("CNAME")
+				m.Content = types.StringValue("web")
+			},
+			wantTtl:   300,
+			wantFqdn:  "www.example.com",
+			wantCalls: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := recordSchema(ctx)
+			client := &mock.API{
+				GetDomainByIdFunc: getExampleDomain,
+				GetRecordsFunc: func(ctx context.Context, domainId int) (*dns.RecordsResponse, error) {
+					return &dns.RecordsResponse{DnsResourceRecordList: existing}, nil
+				},
+			}
+			r := &domainRecordResource{client: client, defaults: &providerDefaults{ttl: tt.defaultTtl}}
+
+			// the configuration only holds the arguments, computed attributes are null
+			config := stored
+			config.Id = types.StringNull()
+			config.Fqdn = types.StringNull()
+			config.AllowOverwrite = types.BoolNull()
+			config.Timeouts = nullTimeouts()
+			tt.config(&config)
+
+			// the plan is the configuration with defaults and unknown values for computed attributes
+			plan := config
+			plan.Id = types.StringUnknown()
+			plan.Fqdn = types.StringUnknown()
+			plan.AllowOverwrite = types.BoolValue(config.AllowOverwrite.ValueBool())
+			if config.Ttl.IsNull() {
+				plan.Ttl = types.Int64Unknown()
+			}
+
+			req := resource.ModifyPlanRequest{
+				Config: tfsdk.Config{Schema: s, Raw: modelState(t, ctx, s, config).Raw},
+				Plan:   tfsdk.Plan{Schema: s},
+				State:  emptyState(ctx, s),
+			}
+			if tt.state != nil {
+				state := *tt.state
+				state.Timeouts = nullTimeouts()
+				plan.Id = state.Id
+				req.State = modelState(t, ctx, s, state)
+			}
+			req.Plan.Raw = modelState(t, ctx, s, plan).Raw
+			resp := &resource.ModifyPlanResponse{Plan: req.Plan}
+			r.ModifyPlan(ctx, req, resp)
+
+			if tt.wantError != "" {
+				assertDiag(t, resp.Diagnostics, diag.SeverityError, tt.wantError)
+				return
+			}
+			assertNoDiags(t, resp.Diagnostics)
+			if tt.wantWarning != "" {
+				assertDiag(t, resp.Diagnostics, diag.SeverityWarning, tt.wantWarning)
+			}
+			var got domainRecordResourceModel
+			assertNoDiags(t, resp.Plan.Get(ctx, &got))
+			if got.Fqdn.ValueString() != tt.wantFqdn {
+				t.Errorf("fqdn = %s, want %s", got.Fqdn, tt.wantFqdn)
+			}
+			if got.Ttl.ValueInt64() != tt.wantTtl {
+				t.Errorf("ttl = %s, want %d", got.Ttl, tt.wantTtl)
+			}
+			if calls := len(client.Calls) > 0; calls != tt.wantCalls {
+				t.Errorf("api called = %v, want %v", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestDomainRecordModifyPlanSharesLookups(t *testing.T) {
+	ctx := context.Background()
+	s := recordSchema(ctx)
+	client := &mock.API{
+		GetDomainByIdFunc: getExampleDomain,
+		GetRecordsFunc: func(ctx context.Context, domainId int) (*dns.RecordsResponse, error) {
+			return &dns.RecordsResponse{}, nil
+		},
+	}
+	r := &domainRecordResource{client: dns.NewCacheClient(client), defaults: &providerDefaults{}}
+
+	for i := 0; i < 300; i++ {
+		config := domainRecordResourceModel{
+			Id:             types.StringNull(),
+			DomainId:       types.Int64Value(1),
+			Name:           types.StringValue(fmt.Sprintf("host%d", i)),
+			Type:           types.StringValue("A"),
+			Ttl:            types.Int64Value(300),
+			Content:        types.StringValue("10.0.0.1"),
+			Fqdn:           types.StringNull(),
+			AllowOverwrite: types.BoolNull(),
+			Timeouts:       nullTimeouts(),
+		}
+		plan := config
+		plan.Id = types.StringUnknown()
+		plan.Fqdn = types.StringUnknown()
+		plan.AllowOverwrite = types.BoolValue(false)
+		req := resource.ModifyPlanRequest{
+			Config: tfsdk.Config{Schema: s, Raw: modelState(t, ctx, s, config).Raw},
+			Plan:   tfsdk.Plan{Schema: s, Raw: modelState(t, ctx, s, plan).Raw},
+			State:  emptyState(ctx, s),
+		}
+		resp := &resource.ModifyPlanResponse{Plan: req.Plan}
+		r.ModifyPlan(ctx, req, resp)
+		assertNoDiags(t, resp.Diagnostics)
+	}
+
+	for _, method := range []string{"GetDomainById", "GetRecords"} {
+		calls := 0
+		for _, call := range client.Calls {
+			if call.Method == method {
+				calls++
+			}
+		}
+		if calls != 1 {
+			t.Errorf("got %d %s calls for 300 records, want 1", calls, method)
+		}
+	}
+}
+
+func TestDomainRecordRead(t *testing.T) {
+	stored := domainRecordResourceModel{
+		Id:       types.StringValue("1:10"),
+		DomainId: types.Int64Value(1),
+		Name:     types.StringValue("www"),
+		Type:     types.StringValue("A"),
+		Ttl:      types.Int64Value(300),
+		Content:  types.StringValue("10.0.0.1"),
+		Timeouts: nullTimeouts(),
+	}
+
+	tests := []struct {
+		name string
+		// storedName is the name in the state, www if empty
+		storedName  string
+		domainErr   error
+		records     []*dns.Record
+		wantRemoved bool
+		wantId      string
+		wantName    string
+		wantFqdn    string
+		wantTtl     int64
+		wantError   string
+	}{
+		{
+			name:     "unchanged record",
+			records:  []*dns.Record{{DnsResourceRecordId: "10", DnsDomainId: 1, Name: "www", Type: "A", Content: "10.0.0.1", Ttl: 300}},
+			wantId:   "1:10",
+			wantName: "www",
+			wantFqdn: "www.example.com",
+			wantTtl:  300,
+		},
+		{
+			name:       "name in other case is kept",
+			storedName: "WWW",
+			records:    []*dns.Record{{DnsResourceRecordId: "10", DnsDomainId: 1, Name: "www", Type: "A", Content: "10.0.0.1", Ttl: 300}},
+			wantId:     "1:10",
+			wantName:   "WWW",
+			wantFqdn:   "www.example.com",
+			wantTtl:    300,
+		},
+		{
+			name:       "fully qualified name is kept",
+			storedName: "www.example.com.",
+			records:    []*dns.Record{{DnsResourceRecordId: "10", DnsDomainId: 1, Name: "www", Type: "A", Content: "10.0.0.1", Ttl: 300}},
+			wantId:     "1:10",
+			wantName:   "www.example.com.",
+			wantFqdn:   "www.example.com",
+			wantTtl:    300,
+		},
+		{
+			name:     "changed ttl",
+			records:  []*dns.Record{{DnsResourceRecordId: "10", DnsDomainId: 1, Name: "www", Type: "A", Content: "10.0.0.1", Ttl: 60}},
+			wantId:   "1:10",
+			wantName: "www",
+			wantFqdn: "www.example.com",
+			wantTtl:  60,
+		},
+		{
+			name:     "renamed outside of terraform",
+			records:  []*dns.Record{{DnsResourceRecordId: "10", DnsDomainId: 1, Name: "web", Type: "A", Content: "10.0.0.1", Ttl: 300}},
+			wantId:   "1:10",
+			wantName: "web",
+			wantFqdn: "web.example.com",
+			wantTtl:  300,
+		},
+		{
+			name:     "reissued record id",
+			records:  []*dns.Record{{DnsResourceRecordId: "11", DnsDomainId: 1, Name: "www", Type: "A", Content: "10.0.0.1", Ttl: 300}},
+			wantId:   "1:11",
+			wantName: "www",
+			wantFqdn: "www.example.com",
+			wantTtl:  300,
+		},
+		{
+			name: "ambiguous fingerprint",
+			records: []*dns.Record{
+				{DnsResourceRecordId: "11", DnsDomainId: 1, Name: "www", Type: "A", Content: "10.0.0.1", Ttl: 300},
+				{DnsResourceRecordId: "12", DnsDomainId: 1, Name: "www", Type: "A", Content: "10.0.0.1", Ttl: 60},
+			},
+			wantError: "Unable to find record",
+		},
+		{
+			name:        "deleted record",
+			records:     []*dns.Record{{DnsResourceRecordId: "11", DnsDomainId: 1, Name: "www", Type: "A", Content: "10.0.0.2", Ttl: 300}},
+			wantRemoved: true,
+		},
+		{
+			name:        "deleted domain",
+			domainErr:   &api.RequestError{Method: http.MethodGet, StatusCode: http.StatusNotFound},
+			wantRemoved: true,
+		},
+		{
+			name:      "failed domain lookup",
+			domainErr: &api.RequestError{Method: http.MethodGet, StatusCode: http.StatusInternalServerError},
+			wantError: "status code 500",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := recordSchema(ctx)
+			r := &domainRecordResource{client: &mock.API{
+				GetDomainByIdFunc: func(ctx context.Context, domainId string) (*dns.DomainResponse, error) {
+					if tt.domainErr != nil {
+						return nil, tt.domainErr
+					}
+					return getExampleDomain(ctx, domainId)
+				},
+				GetRecordsFunc: func(ctx context.Context, domainId int) (*dns.RecordsResponse, error) {
+					return &dns.RecordsResponse{DnsResourceRecordList: tt.records}, nil
+				},
+			}}
+
+			model := stored
+			if tt.storedName != "" {
+				model.Name = types.StringValue(tt.storedName)
+			}
+			state := modelState(t, ctx, s, model)
+			resp := &resource.ReadResponse{State: state}
+			r.Read(ctx, resource.ReadRequest{State: state}, resp)
+
+			if tt.wantError != "" {
+				assertDiag(t, resp.Diagnostics, diag.SeverityError, tt.wantError)
+				return
+			}
+			assertNoDiags(t, resp.Diagnostics)
+			if removed := resp.State.Raw.IsNull(); removed != tt.wantRemoved {
+				t.Fatalf("removed = %v, want %v", removed, tt.wantRemoved)
+			}
+			if tt.wantRemoved {
+				return
+			}
+			var got domainRecordResourceModel
+			assertNoDiags(t, resp.State.Get(ctx, &got))
+			if got.Id.ValueString() != tt.wantId || got.Name.ValueString() != tt.wantName || got.Ttl.ValueInt64() != tt.wantTtl {
+				t.Errorf("id, name, ttl = %s, %s, %s, want %s, %s, %d", got.Id, got.Name, got.Ttl, tt.wantId, tt.wantName,
+					tt.wantTtl)
+			}
+			if got.Fqdn.ValueString() != tt.wantFqdn {
+				t.Errorf("fqdn = %s, want %s", got.Fqdn, tt.wantFqdn)
+			}
+		})
+	}
+}
+
+func TestDomainRecordUpdate(t *testing.T) {
+	stored := domainRecordResourceModel{
+		Id:       types.StringValue("1:10"),
+		DomainId: types.Int64Value(1),
+		Name:     types.StringValue("www"),
+		Type:     types.StringValue("A"),
+		Ttl:      types.Int64Value(300),
+		Content:  types.StringValue("10.0.0.1"),
+		Timeouts: nullTimeouts(),
+	}
+	updated := stored
+	updated.Id = types.StringUnknown()
+	updated.Ttl = types.Int64Value(600)
+	updated.Content = types.StringValue("10.0.0.2")
+
+	tests := []struct {
+		name       string
+		records    []*dns.Record
+		recordsErr error
+		wantId     string
+		wantError  string
+	}{
+		{
+			name:    "same record id",
+			records: []*dns.Record{{DnsResourceRecordId: "10", DnsDomainId: 1, Name: "www", Type: "A", Content: "10.0.0.2", Ttl: 600}},
+			wantId:  "1:10",
+		},
+		{
+			name: "reissued record id",
+			records: []*dns.Record{
+				{DnsResourceRecordId: "11", DnsDomainId: 1, Name: "www", Type: "A", Content: "10.0.0.1", Ttl: 300},
+				{DnsResourceRecordId: "12", DnsDomainId: 1, Name: "www", Type: "A", Content: "10.0.0.2", Ttl: 600},
+			},
+			wantId: "1:12",
+		},
+		{
+			// the state keeps the last known id, so the next refresh finds the record by its fingerprint
+			name:       "failed lookup of the new id",
+			recordsErr: &api.RequestError{Method: http.MethodGet, StatusCode: http.StatusBadGateway},
+			wantId:     "1:10",
+			wantError:  "status code 502",
+		},
+		{
+			name:      "updated record gone",
+			wantId:    "1:10",
+			wantError: "Unable to find updated record",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := recordSchema(ctx)
+			client := &mock.API{
+				GetDomainByIdFunc: getExampleDomain,
+				UpdateRecordFunc: func(ctx context.Context, domainId int, recordId string, content string, ttl int) (*dns.RecordUpdateResponse, error) {
+					return &dns.RecordUpdateResponse{}, nil
+				},
+				GetRecordsFunc: func(ctx context.Context, domainId int) (*dns.RecordsResponse, error) {
+					if tt.recordsErr != nil {
+						return nil, tt.recordsErr
+					}
+					return &dns.RecordsResponse{DnsResourceRecordList: tt.records}, nil
+				},
+			}
+			r := &domainRecordResource{client: client}
+
+			state := modelState(t, ctx, s, stored)
+			plan := modelState(t, ctx, s, updated)
+			resp := &resource.UpdateResponse{State: state}
+			r.Update(ctx, resource.UpdateRequest{Plan: tfsdk.Plan{Schema: s, Raw: plan.Raw}, State: state}, resp)
+
+			if tt.wantError != "" {
+				assertDiag(t, resp.Diagnostics, diag.SeverityError, tt.wantError)
+			} else {
+				assertNoDiags(t, resp.Diagnostics)
+			}
+			var got domainRecordResourceModel
+			assertNoDiags(t, resp.State.Get(ctx, &got))
+			if got.Id.ValueString() != tt.wantId || got.Content.ValueString() != "10.0.0.2" || got.Ttl.ValueInt64() != 600 {
+				t.Errorf("id, content, ttl = %s, %s, %s, want %s with the planned content and ttl", got.Id, got.Content,
+					got.Ttl, tt.wantId)
+			}
+			update := writeCalls(client)[0]
+			if update.Method != "UpdateRecord" || update.Args[1] != "10" || update.Args[2] != "10.0.0.2" || update.Args[3] != 600 {
+				t.Errorf("first write %v, want the update of record 10", update)
+			}
+		})
+	}
+}
+
+func TestDomainRecordRename(t *testing.T) {
+	stored := domainRecordResourceModel{
+		Id:       types.StringValue("1:10"),
+		DomainId: types.Int64Value(1),
+		Name:     types.StringValue("www"),
+		Type:     types.StringValue("A"),
+		Ttl:      types.Int64Value(300),
+		Content:  types.StringValue("10.0.0.1"),
+		Timeouts: nullTimeouts(),
+	}
+	renamed := stored
+	renamed.Id = types.StringUnknown()
+	renamed.Name = types.StringValue("web")
+
+	tests := []struct {
+		name      string
+		deleteErr error
+		wantError string
+	}{
+		{name: "old record deleted"},
+		{
+			name:      "old record not deleted",
+			deleteErr: &api.RequestError{Method: http.MethodDelete, StatusCode: http.StatusInternalServerError},
+			wantError: "Unable to delete the record 10 after renaming it",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := recordSchema(ctx)
+			client := &mock.API{
+				GetDomainByIdFunc: getExampleDomain,
+				CreateRecordFunc: func(ctx context.Context, records *dns.RecordCreateRequest) (*dns.RecordsResponse, error) {
+					created := records.DnsResourceRecordList[0]
+					created.DnsResourceRecordId = "20"
+					return &dns.RecordsResponse{DnsResourceRecordList: []*dns.Record{&created}}, nil
+				},
+				DeleteRecordFunc: func(ctx context.Context, domainId int, recordId string) (*dns.RecordUpdateResponse, error) {
+					return &dns.RecordUpdateResponse{}, tt.deleteErr
+				},
+			}
+			r := &domainRecordResource{client: client}
+
+			state := modelState(t, ctx, s, stored)
+			plan := modelState(t, ctx, s, renamed)
+			resp := &resource.UpdateResponse{State: state}
+			r.Update(ctx, resource.UpdateRequest{Plan: tfsdk.Plan{Schema: s, Raw: plan.Raw}, State: state}, resp)
+
+			if tt.wantError != "" {
+				assertDiag(t, resp.Diagnostics, diag.SeverityError, tt.wantError)
+			} else {
+				assertNoDiags(t, resp.Diagnostics)
+			}
+			// the state points at the new record even if the old one is left over
+			var got domainRecordResourceModel
+			assertNoDiags(t, resp.State.Get(ctx, &got))
+			if got.Id.ValueString() != "1:20" || got.Name.ValueString() != "web" {
+				t.Errorf("id, name = %s, %s, want 1:20, web", got.Id, got.Name)
+			}
+			writes := writeCalls(client)
+			if len(writes) != 2 || writes[0].Method != "CreateRecord" || writes[1].Method != "DeleteRecord" ||
+				writes[1].Args[1] != "10" {
+				t.Errorf("writes %v, want the new record to be created before the old record is deleted", writes)
+			}
+		})
+	}
+}
+
+func TestDomainRecordUpgradeStateV0(t *testing.T) {
+	ctx := context.Background()
+	r := &domainRecordResource{}
+	upgrader := r.UpgradeState(ctx)[0]
+
+	prior := tfsdk.State{Schema: *upgrader.PriorSchema, Raw: tftypes.NewValue(upgrader.PriorSchema.Type().TerraformType(ctx), nil)}
+	assertNoDiags(t, prior.Set(ctx, &domainRecordResourceModelV0{
+		Id:       types.StringValue("10"),
+		DomainId: types.Int64Value(1),
+		Name:     types.StringValue("www"),
+		Type:     types.StringValue("A"),
+		Ttl:      types.Int64Value(300),
+		Content:  types.StringValue("10.0.0.1"),
+		Timeouts: nullTimeouts(),
+	}))
+	resp := &resource.UpgradeStateResponse{State: emptyState(ctx, recordSchema(ctx))}
+	upgrader.StateUpgrader(ctx, resource.UpgradeStateRequest{State: &prior}, resp)
+	assertNoDiags(t, resp.Diagnostics)
+
+	var got domainRecordResourceModel
+	assertNoDiags(t, resp.State.Get(ctx, &got))
+	if got.Id.ValueString() != "1:10" {
+		t.Errorf("id = %s, want 1:10", got.Id)
+	}
+	if got.Name.ValueString() != "www" || got.Ttl.ValueInt64() != 300 || got.Content.ValueString() != "10.0.0.1" {
+		t.Errorf("attributes have not been kept: %+v", got)
+	}
+}
+
+// recordId returns the id of record or "" for nil.
+func recordId(record *dns.Record) string {
+	if record == nil {
+		return ""
+	}
+	return record.DnsResourceRecordId
+}
+
+// getExampleDomain is a dns.API GetDomainById returning the domain example.com.
+func getExampleDomain(ctx context.Context, domainId string) (*dns.DomainResponse, error) {
+	return &dns.DomainResponse{DnsDomain: dns.Domain{DnsDomainId: 1, Name: "example.com"}}, nil
+}
+
+// writeCalls returns the calls of client that change domains or records.
+func writeCalls(client *mock.API) []mock.Call {
+	var calls []mock.Call
+	for _, call := range client.Calls {
+		if !strings.HasPrefix(call.Method, "Get") && !strings.HasPrefix(call.Method, "Search") {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+func recordSchema(ctx context.Context) schema.Schema {
+	resp := &resource.SchemaResponse{}
+	(&domainRecordResource{}).Schema(ctx, resource.SchemaRequest{}, resp)
+	return resp.Schema
+}
+
+func nullTimeouts() timeouts.Value {
+	return timeouts.Value{Object: types.ObjectNull(map[string]attr.Type{
+		"create": types.StringType,
+		"update": types.StringType,
+		"delete": types.StringType,
+	})}
+}
+
+func emptyState(ctx context.Context, s schema.Schema) tfsdk.State {
+	return tfsdk.State{Schema: s, Raw: tftypes.NewValue(s.Type().TerraformType(ctx), nil)}
+}
+
+// modelState returns a state of the schema holding model.
+func modelState(t *testing.T, ctx context.Context, s schema.Schema, model interface{}) tfsdk.State {
+	t.Helper()
+	state := emptyState(ctx, s)
+	assertNoDiags(t, state.Set(ctx, model))
+	return state
+}
+
+func assertNoDiags(t *testing.T, diags diag.Diagnostics) {
+	t.Helper()
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+}
+
+// assertDiag checks that diags contain a diagnostic of severity with summary in its summary or detail.
+func assertDiag(t *testing.T, diags diag.Diagnostics, severity diag.Severity, summary string) {
+	t.Helper()
+	for _, d := range diags {
+		if d.Severity() == severity && (strings.Contains(d.Summary(), summary) || strings.Contains(d.Detail(), summary)) {
+			return
+		}
+	}
+	t.Fatalf("no %s diagnostic %q in %v", severity, summary, diags)
+}
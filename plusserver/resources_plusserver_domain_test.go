@@ -0,0 +1,152 @@
+package plusserver
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"testing"
+)
+
+func TestDomainUpgradeStateV0(t *testing.T) {
+	tests := []struct {
+		name           string
+		companyId      string
+		contractId     string
+		wantCompanyId  types.String
+		wantContractId types.String
+	}{
+		{name: "unset ids", wantCompanyId: types.StringNull(), wantContractId: types.StringNull()},
+		{
+			name:           "set ids",
+			companyId:      "12345",
+			contractId:     "67890",
+			wantCompanyId:  types.StringValue("12345"),
+			wantContractId: types.StringValue("67890"),
+		},
+		{
+			name:           "only contract id",
+			contractId:     "67890",
+			wantCompanyId:  types.StringNull(),
+			wantContractId: types.StringValue("67890"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			r := &domainResource{}
+			upgrader := r.UpgradeState(ctx)[0]
+			schemaResp := &resource.SchemaResponse{}
+			r.Schema(ctx, resource.SchemaRequest{}, schemaResp)
+
+			// states written by the SDKv2 provider hold empty strings for unset arguments
+			prior := tfsdk.State{Schema: *upgrader.PriorSchema,
+				Raw: tftypes.NewValue(upgrader.PriorSchema.Type().TerraformType(ctx), nil)}
+			assertNoDiags(t, prior.Set(ctx, &domainResourceModel{
+				Id:                             types.StringValue("1"),
+				UnicodeName:                    types.StringValue("example.com"),
+				DomainId:                       types.Int64Value(1),
+				CompanyId:                      types.StringValue(tt.companyId),
+				Protected:                      types.BoolValue(false),
+				ReplicationType:                types.StringValue("None"),
+				DnsNameserverPairName:          types.StringValue("ns1.plusserver.com"),
+				ReplicationMasterIpAddressList: types.ListValueMust(types.StringType, nil),
+				ContractId:                     types.StringValue(tt.contractId),
+				CreatedAt:                      types.StringValue("2021-10-13T00:00:00Z"),
+				Timeouts:                       nullTimeouts(),
+			}))
+			resp := &resource.UpgradeStateResponse{State: emptyState(ctx, schemaResp.Schema)}
+			upgrader.StateUpgrader(ctx, resource.UpgradeStateRequest{State: &prior}, resp)
+			assertNoDiags(t, resp.Diagnostics)
+
+			var got domainResourceModel
+			assertNoDiags(t, resp.State.Get(ctx, &got))
+			if !got.CompanyId.Equal(tt.wantCompanyId) || !got.ContractId.Equal(tt.wantContractId) {
+				t.Errorf("company_id, contract_id = %s, %s, want %s, %s", got.CompanyId, got.ContractId,
+					tt.wantCompanyId, tt.wantContractId)
+			}
+			if got.Id.ValueString() != "1" || got.UnicodeName.ValueString() != "example.com" {
+				t.Errorf("attributes have not been kept: %+v", got)
+			}
+		})
+	}
+}
+
+func TestDomainModifyPlan(t *testing.T) {
+	tests := []struct {
+		name           string
+		companyId      types.String
+		defaults       providerDefaults
+		wantCompanyId  types.String
+		wantContractId types.String
+	}{
+		{
+			name:           "no defaults",
+			companyId:      types.StringNull(),
+			wantCompanyId:  types.StringNull(),
+			wantContractId: types.StringNull(),
+		},
+		{
+			name:           "defaults",
+			companyId:      types.StringNull(),
+			defaults:       providerDefaults{companyId: "12345", contractId: "67890"},
+			wantCompanyId:  types.StringValue("12345"),
+			wantContractId: types.StringValue("67890"),
+		},
+		{
+			name:           "configured company id",
+			companyId:      types.StringValue("54321"),
+			defaults:       providerDefaults{companyId: "12345", contractId: "67890"},
+			wantCompanyId:  types.StringValue("54321"),
+			wantContractId: types.StringValue("67890"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			r := &domainResource{defaults: &tt.defaults}
+			schemaResp := &resource.SchemaResponse{}
+			r.Schema(ctx, resource.SchemaRequest{}, schemaResp)
+			s := schemaResp.Schema
+
+			config := domainResourceModel{
+				Id:                             types.StringNull(),
+				UnicodeName:                    types.StringValue("example.com"),
+				DomainId:                       types.Int64Null(),
+				CompanyId:                      tt.companyId,
+				Protected:                      types.BoolNull(),
+				ReplicationType:                types.StringNull(),
+				DnsNameserverPairName:          types.StringNull(),
+				ReplicationMasterIpAddressList: types.ListNull(types.StringType),
+				ContractId:                     types.StringNull(),
+				CreatedAt:                      types.StringNull(),
+				Timeouts:                       nullTimeouts(),
+			}
+			plan := config
+			plan.Id = types.StringUnknown()
+			plan.CompanyId = types.StringUnknown()
+			if !tt.companyId.IsNull() {
+				plan.CompanyId = tt.companyId
+			}
+			plan.ContractId = types.StringUnknown()
+			req := resource.ModifyPlanRequest{
+				Config: tfsdk.Config{Schema: s, Raw: modelState(t, ctx, s, config).Raw},
+				Plan:   tfsdk.Plan{Schema: s, Raw: modelState(t, ctx, s, plan).Raw},
+				State:  emptyState(ctx, s),
+			}
+			resp := &resource.ModifyPlanResponse{Plan: req.Plan}
+			r.ModifyPlan(ctx, req, resp)
+			assertNoDiags(t, resp.Diagnostics)
+
+			var got domainResourceModel
+			assertNoDiags(t, resp.Plan.Get(ctx, &got))
+			if !got.CompanyId.Equal(tt.wantCompanyId) || !got.ContractId.Equal(tt.wantContractId) {
+				t.Errorf("company_id, contract_id = %s, %s, want %s, %s", got.CompanyId, got.ContractId,
+					tt.wantCompanyId, tt.wantContractId)
+			}
+		})
+	}
+}